@@ -0,0 +1,115 @@
+package buildinfo
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	Version = "0.0.1"
+
+	gitDirName = ".git"
+	refPrefix  = "ref: "
+)
+
+// gitCommitEnvVars are checked in order before falling back to the .git directory,
+// because CI checkouts are often shallow or detached from the repository.
+var gitCommitEnvVars = []string{"ANDMERADA_GIT_COMMIT", "GITHUB_SHA", "CI_COMMIT_SHA"}
+
+// Hostname returns the name of the machine that runs Andmerada, or an empty string when it is unknown.
+func Hostname() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return ""
+	}
+
+	return hostname
+}
+
+// GitCommit returns the commit the migrations in dir are deployed from, or an empty string when it is unknown.
+func GitCommit(dir string) string {
+	for _, name := range gitCommitEnvVars {
+		if commit := strings.TrimSpace(os.Getenv(name)); commit != "" {
+			return commit
+		}
+	}
+
+	gitDir, found := findGitDir(dir)
+	if !found {
+		return ""
+	}
+
+	return resolveHead(gitDir)
+}
+
+func findGitDir(dir string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+
+	for {
+		candidate := filepath.Join(dir, gitDirName)
+
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return candidate, true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+
+		dir = parent
+	}
+}
+
+func resolveHead(gitDir string) string {
+	head, err := readTrimmed(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+
+	ref, isSymbolic := strings.CutPrefix(head, refPrefix)
+	if !isSymbolic {
+		return head
+	}
+
+	if commit, err := readTrimmed(filepath.Join(gitDir, filepath.FromSlash(ref))); err == nil {
+		return commit
+	}
+
+	return findPackedRef(gitDir, ref)
+}
+
+func findPackedRef(gitDir, ref string) string {
+	file, err := os.Open(filepath.Join(gitDir, "packed-refs"))
+	if err != nil {
+		return ""
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		commit, name, found := strings.Cut(scanner.Text(), " ")
+
+		if found && name == ref {
+			return commit
+		}
+	}
+
+	return ""
+}
+
+func readTrimmed(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err //nolint:wrapcheck
+	}
+
+	return strings.TrimSpace(string(content)), nil
+}
@@ -0,0 +1,80 @@
+package buildinfo_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/servletcloud/Andmerada/internal/buildinfo"
+	"github.com/servletcloud/Andmerada/internal/osutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const commit = "8f2c1b7e4d9a0c3b5e6f7a8b9c0d1e2f3a4b5c6d"
+
+//nolint:paralleltest
+func TestGitCommit(t *testing.T) {
+	unsetCommitEnv := func(t *testing.T) {
+		t.Helper()
+
+		for _, name := range []string{"ANDMERADA_GIT_COMMIT", "GITHUB_SHA", "CI_COMMIT_SHA"} {
+			t.Setenv(name, "")
+		}
+	}
+
+	createGitDir := func(t *testing.T, files map[string]string) string {
+		t.Helper()
+
+		root := t.TempDir()
+
+		for name, content := range files {
+			path := filepath.Join(root, ".git", filepath.FromSlash(name))
+
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), osutil.DirPerm0755))
+			require.NoError(t, os.WriteFile(path, []byte(content), osutil.FilePerm0644))
+		}
+
+		migrationsDir := filepath.Join(root, "db", "migrations")
+		require.NoError(t, os.MkdirAll(migrationsDir, osutil.DirPerm0755))
+
+		return migrationsDir
+	}
+
+	t.Run("prefers the environment", func(t *testing.T) {
+		unsetCommitEnv(t)
+		t.Setenv("GITHUB_SHA", commit)
+
+		assert.Equal(t, commit, buildinfo.GitCommit(t.TempDir()))
+	})
+
+	t.Run("resolves a loose branch ref in a parent directory", func(t *testing.T) {
+		unsetCommitEnv(t)
+
+		dir := createGitDir(t, map[string]string{
+			"HEAD":            "ref: refs/heads/main\n",
+			"refs/heads/main": commit + "\n",
+		})
+
+		assert.Equal(t, commit, buildinfo.GitCommit(dir))
+	})
+
+	t.Run("resolves a packed branch ref", func(t *testing.T) {
+		unsetCommitEnv(t)
+
+		dir := createGitDir(t, map[string]string{
+			"HEAD":        "ref: refs/heads/main\n",
+			"packed-refs": "# pack-refs with: peeled fully-peeled sorted\n" + commit + " refs/heads/main\n",
+		})
+
+		assert.Equal(t, commit, buildinfo.GitCommit(dir))
+	})
+
+	t.Run("resolves a detached HEAD", func(t *testing.T) {
+		unsetCommitEnv(t)
+
+		dir := createGitDir(t, map[string]string{"HEAD": commit + "\n"})
+
+		assert.Equal(t, commit, buildinfo.GitCommit(dir))
+	})
+}
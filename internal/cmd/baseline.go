@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/servletcloud/Andmerada/internal/cmd/descriptions"
+	"github.com/servletcloud/Andmerada/internal/migrator"
+	"github.com/servletcloud/Andmerada/internal/source"
+	"github.com/spf13/cobra"
+)
+
+type baselineFunc func(context.Context, migrator.BaselineOptions, *migrator.BaselineReport) error
+
+func baselineCommand() *cobra.Command {
+	description := descriptions.BaselineDescription()
+	baseline := baselineCmdRunner{}
+
+	//nolint:exhaustruct
+	command := &cobra.Command{
+		Use:   description.Use,
+		Short: description.Short,
+		Long:  description.Long,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			upTo, _ := cmd.Flags().GetString("up-to")
+			id := mustParseMigrationID(upTo, "--up-to")
+
+			report := baseline.Run(cmd, id, migrator.Baseline)
+
+			log.Printf("Baselined %d migrations up to %v.", len(report.MarkedIDs), id)
+		},
+		Example: `andmerada baseline --up-to=20241225112129`,
+	}
+
+	addDatabaseURLFlag(command)
+	addLockTimeoutFlag(command)
+
+	command.Flags().String("up-to", "", "The ID of the last migration to mark as applied.")
+
+	_ = command.MarkFlagRequired("up-to")
+
+	return command
+}
+
+func markAppliedCommand() *cobra.Command {
+	description := descriptions.MarkAppliedDescription()
+	baseline := baselineCmdRunner{}
+
+	//nolint:exhaustruct
+	command := &cobra.Command{
+		Use:   description.Use,
+		Short: description.Short,
+		Long:  description.Long,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			baseline.Run(cmd, mustParseMigrationID(args[0], "migration ID"), migrator.MarkApplied)
+		},
+		Example: `andmerada mark-applied 20241225112129`,
+	}
+
+	addDatabaseURLFlag(command)
+	addLockTimeoutFlag(command)
+
+	return command
+}
+
+func markPendingCommand() *cobra.Command {
+	description := descriptions.MarkPendingDescription()
+	baseline := baselineCmdRunner{}
+
+	//nolint:exhaustruct
+	command := &cobra.Command{
+		Use:   description.Use,
+		Short: description.Short,
+		Long:  description.Long,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			baseline.Run(cmd, mustParseMigrationID(args[0], "migration ID"), migrator.MarkPending)
+		},
+		Example: `andmerada mark-pending 20241225112129`,
+	}
+
+	addDatabaseURLFlag(command)
+	addLockTimeoutFlag(command)
+
+	return command
+}
+
+type baselineCmdRunner struct {
+	migrateErrorPrinter
+}
+
+func (b *baselineCmdRunner) Run(cmd *cobra.Command, id source.ID, baselineFunc baselineFunc) migrator.BaselineReport {
+	project, environment := mustLoadProjectEnvironment(cmd)
+
+	databaseURL := mustGetDatabaseURL(cmd, environment)
+
+	lockTimeout, _ := cmd.Flags().GetDuration("lock-timeout")
+
+	mustConfirmWrite(cmd, environment)
+
+	options := migrator.BaselineOptions{
+		MaxSQLFileSize: MaxSQLFileSizeBytes,
+		DatabaseURL:    databaseURL,
+		Project:        project,
+		ID:             id,
+		LockTimeout:    lockTimeout,
+	}
+	report := migrator.BaselineReport{MarkedIDs: nil}
+
+	if err := baselineFunc(cmd.Context(), options, &report); err != nil {
+		b.printError(err)
+		os.Exit(exitCodeCriticalFailure)
+	}
+
+	return report
+}
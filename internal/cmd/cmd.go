@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"os"
+
+	"github.com/servletcloud/Andmerada/internal/buildinfo"
 	"github.com/spf13/cobra"
 )
 
@@ -11,14 +14,43 @@ func RootCommand() *cobra.Command {
 		Annotations: map[string]string{
 			cobra.CommandDisplayNameAnnotation: "andmerada",
 		},
-		Version: "0.0.1",
+		Version: buildinfo.Version,
 	}
 
+	rootCmd.PersistentFlags().String(
+		"project-dir",
+		os.Getenv("ANDMERADA_PROJECT_DIR"),
+		"The directory with andmerada.yml. Defaults to the ANDMERADA_PROJECT_DIR environment variable, "+
+			"then to the nearest directory with andmerada.yml from the current one upwards.",
+	)
+
+	rootCmd.PersistentFlags().String(
+		"env",
+		os.Getenv("ANDMERADA_ENV"),
+		"Selects a named environment of andmerada.yml. Defaults to the ANDMERADA_ENV environment variable.",
+	)
+
+	rootCmd.PersistentFlags().Bool(
+		"yes",
+		false,
+		"Confirms upfront that a command may write to the database of a protected environment.",
+	)
+
 	rootCmd.AddCommand(
 		initProjectCommand(),
 		createMigrationCommand(),
 		lintCommand(),
 		migrateCommand(),
+		rollbackCommand(),
+		verifyCommand(),
+		statusCommand(),
+		lockCommand(),
+		showDDLCommand(),
+		baselineCommand(),
+		markAppliedCommand(),
+		markPendingCommand(),
+		historyCommand(),
+		selfUpgradeCommand(),
 	)
 
 	return rootCmd
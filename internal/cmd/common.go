@@ -1,14 +1,22 @@
 package cmd
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/dustin/go-humanize"
+	"github.com/servletcloud/Andmerada/internal/migrator"
+	"github.com/servletcloud/Andmerada/internal/osutil"
+	"github.com/servletcloud/Andmerada/internal/placeholder"
 	"github.com/servletcloud/Andmerada/internal/project"
+	"github.com/servletcloud/Andmerada/internal/source"
 	"github.com/servletcloud/Andmerada/internal/ymlutil"
+	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
 
@@ -18,25 +26,219 @@ func ensureProjectInitialized(dir string) {
 	_ = mustLoadProject(dir)
 }
 
+// mustGetProjectDir returns the absolute directory of the project: --project-dir when it is given,
+// otherwise the nearest directory with andmerada.yml from the current one upwards,
+// otherwise the current directory.
+func mustGetProjectDir(cmd *cobra.Command) string {
+	if dir, _ := cmd.Flags().GetString("project-dir"); dir != "" {
+		absoluteDir, err := filepath.Abs(dir)
+		if err != nil {
+			log.Fatalf("Cannot resolve --project-dir %v: %v", dir, err)
+		}
+
+		return absoluteDir
+	}
+
+	currentDir := osutil.GetwdOrPanic()
+
+	dir, err := project.Find(currentDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return currentDir
+		}
+
+		log.Fatalf("Cannot find the project: %v", err)
+	}
+
+	return dir
+}
+
 func mustLoadProject(dir string) project.Project {
-	project, err := project.Load(dir)
+	loaded, err := project.Load(dir)
 
 	if err == nil {
-		return project
+		return loaded
 	}
 
+	configPath := project.ConfigPath(dir)
+
 	if errors.Is(err, os.ErrNotExist) {
-		log.Fatalf("Project is not initialized. Initialize with `andmerada init %v`", dir)
+		log.Fatalf("Project is not initialized, %v does not exist. Initialize with `andmerada init %v` "+
+			"or point to the project with --project-dir.", configPath, dir)
 	}
 
 	if schemaError := new(ymlutil.ValidationError); errors.As(err, &schemaError) {
-		log.Fatalf("Schema validation failed for andmerada.yml:\n%v", schemaError)
+		log.Fatalf("Schema validation failed for %v:\n%v", configPath, schemaError)
 	}
 
 	var yamlError *yaml.TypeError
 	if errors.As(err, &yamlError) {
-		log.Fatalf("Cannot parse andmerada.yml: %v", yamlError)
+		log.Fatalf("Cannot parse %v: %v", configPath, yamlError)
+	}
+
+	if errors.Is(err, project.ErrUnknownCallback) {
+		log.Fatalf("Cannot load callbacks: %v. Rename or remove the file.", err)
 	}
 
 	panic(fmt.Sprintf("Cannot read or parse the project: %v", err))
 }
+
+// mustLoadProjectEnvironment loads the project of mustGetProjectDir and applies the environment
+// selected with --env to it. Without --env the returned environment is empty.
+func mustLoadProjectEnvironment(cmd *cobra.Command) (project.Project, project.Environment) {
+	loaded := mustLoadProject(mustGetProjectDir(cmd))
+
+	name, _ := cmd.Flags().GetString("env")
+	if name == "" {
+		return loaded, project.Environment{} //nolint:exhaustruct
+	}
+
+	environment, err := loaded.UseEnvironment(name)
+	if err != nil {
+		log.Fatalf("Cannot use --env %v: %v", name, err)
+	}
+
+	return loaded, environment
+}
+
+// mustConfirmWrite asks for confirmation before the command writes to the database of a protected
+// environment. --yes confirms upfront. Without a terminal to ask on, the command fails instead.
+func mustConfirmWrite(cmd *cobra.Command, environment project.Environment) {
+	if !environment.Protected {
+		return
+	}
+
+	if yes, _ := cmd.Flags().GetBool("yes"); yes {
+		return
+	}
+
+	stdin, isFile := cmd.InOrStdin().(*os.File)
+	if !isFile || !isTerminal(stdin) {
+		log.Fatalf("Environment %q is protected. Pass --yes to confirm %q non-interactively.",
+			environment.Name, cmd.CommandPath())
+	}
+
+	log.Printf("Environment %q is protected. Type its name to continue with %q:", environment.Name, cmd.CommandPath())
+
+	answer, _ := bufio.NewReader(stdin).ReadString('\n')
+
+	if strings.TrimSpace(answer) != environment.Name {
+		log.Fatalln("Aborted, nothing was changed.")
+	}
+}
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// getStringFlag returns the flag when it is given on the command line, otherwise the value of the
+// environment when it sets one, otherwise the default of the flag.
+func getStringFlag(cmd *cobra.Command, name string, environment string) string {
+	value, _ := cmd.Flags().GetString(name)
+
+	if !cmd.Flags().Changed(name) && environment != "" {
+		return environment
+	}
+
+	return value
+}
+
+// getBoolFlag is getStringFlag for boolean flags.
+func getBoolFlag(cmd *cobra.Command, name string, environment *bool) bool {
+	value, _ := cmd.Flags().GetBool(name)
+
+	if !cmd.Flags().Changed(name) && environment != nil {
+		return *environment
+	}
+
+	return value
+}
+
+func addDatabaseURLFlag(command *cobra.Command) {
+	command.Flags().String(
+		"database-url",
+		os.Getenv("DATABASE_URL"),
+		"Database connection URL. Defaults to database_url of the --env environment, "+
+			"then to the DATABASE_URL environment variable.",
+	)
+}
+
+func mustGetDatabaseURL(cmd *cobra.Command, environment project.Environment) string {
+	databaseURL := getStringFlag(cmd, "database-url", environment.DatabaseURL)
+
+	if len(databaseURL) == 0 {
+		log.Fatalf("Database URL is missing. Set the DATABASE_URL environment variable, specify it " +
+			"using the --database-url flag or select an environment with database_url using --env.")
+	}
+
+	return databaseURL
+}
+
+func addAllowDriftFlag(command *cobra.Command) {
+	command.Flags().Bool(
+		"allow-drift",
+		strings.ToLower(os.Getenv("ALLOW_DRIFT")) == "true",
+		"Reports applied migrations that differ from the files on disk as warnings instead of failing. "+
+			"Defaults to the ALLOW_DRIFT environment variable.",
+	)
+}
+
+func addLockTimeoutFlag(command *cobra.Command) {
+	command.Flags().Duration(
+		"lock-timeout",
+		migrator.DefaultLockTimeout,
+		"How long to wait for another andmerada process to release the migrations lock. Set to 0 to fail immediately.",
+	)
+}
+
+func addSetFlag(command *cobra.Command) {
+	command.Flags().StringArray(
+		"set",
+		nil,
+		"Sets the value of a ${name} placeholder, e.g. --set app_owner=app_owner_stg. May be repeated. "+
+			"Overrides andmerada.yml and "+placeholder.EnvPrefix+"<NAME> environment variables.",
+	)
+}
+
+// mustGetPlaceholders returns the placeholders defined by environment variables and --set flags.
+// They override the placeholders of andmerada.yml, and --set overrides the environment.
+func mustGetPlaceholders(cmd *cobra.Command) placeholder.Values {
+	assignments, _ := cmd.Flags().GetStringArray("set")
+
+	flags := make(placeholder.Values, len(assignments))
+
+	for _, assignment := range assignments {
+		name, value, found := strings.Cut(assignment, "=")
+
+		if !found || name == "" {
+			log.Fatalf("Invalid --set value %q. Expected name=value, e.g. --set app_owner=app_owner_stg.", assignment)
+		}
+
+		flags[name] = value
+	}
+
+	return placeholder.Merge(placeholder.FromEnviron(os.Environ()), flags)
+}
+
+func mustParseMigrationID(value string, what string) source.ID {
+	id := source.NewIDFromString(value)
+
+	if id == source.EmptyMigrationID || len(value) != len(id.String()) {
+		log.Fatalf("Invalid %v value %q. Expected a migration ID like 20241225112129.", what, value)
+	}
+
+	return id
+}
+
+// mustGetMigrationIDFlag returns the migration ID of an optional flag, or EmptyMigrationID when it is not set.
+func mustGetMigrationIDFlag(cmd *cobra.Command, name string) source.ID {
+	value, _ := cmd.Flags().GetString(name)
+
+	if value == "" {
+		return source.EmptyMigrationID
+	}
+
+	return mustParseMigrationID(value, "--"+name)
+}
@@ -5,7 +5,6 @@ import (
 	"log"
 
 	"github.com/servletcloud/Andmerada/internal/cmd/descriptions"
-	"github.com/servletcloud/Andmerada/internal/osutil"
 	"github.com/servletcloud/Andmerada/internal/resources"
 	"github.com/servletcloud/Andmerada/internal/source"
 	"github.com/spf13/cobra"
@@ -20,14 +19,14 @@ func createMigrationCommand() *cobra.Command {
 		Short: description.Short,
 		Long:  description.Long,
 		Args:  cobra.ExactArgs(1),
-		Run: func(_ *cobra.Command, args []string) {
-			currentDir := osutil.GetwdOrPanic()
+		Run: func(cmd *cobra.Command, args []string) {
+			projectDir := mustGetProjectDir(cmd)
 			name := args[0]
 			id := source.NewIDFromNow()
 
-			ensureProjectInitialized(currentDir)
+			ensureProjectInitialized(projectDir)
 
-			result, err := source.Create(currentDir, name, id)
+			result, err := source.Create(projectDir, name, id)
 
 			if err != nil {
 				if errors.Is(err, source.ErrSourceAlreadyExists) {
@@ -42,7 +41,7 @@ func createMigrationCommand() *cobra.Command {
 				log.Println(resources.MsgMigrationNotLatest())
 			}
 
-			log.Println(resources.MsgMigrationCreated(result.BaseDir))
+			log.Println(resources.MsgMigrationCreated(result.FullPath))
 		},
 		Example: `andmerada create-migration "Add users table"`,
 	}
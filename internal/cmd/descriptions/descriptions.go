@@ -21,6 +21,42 @@ var crMigrationRaw string
 //go:embed migrate.txt
 var migrateRaw string
 
+//go:embed rollback.txt
+var rollbackRaw string
+
+//go:embed verify.txt
+var verifyRaw string
+
+//go:embed lock.txt
+var lockRaw string
+
+//go:embed lock_status.txt
+var lockStatusRaw string
+
+//go:embed lock_release.txt
+var lockReleaseRaw string
+
+//go:embed status.txt
+var statusRaw string
+
+//go:embed show_ddl.txt
+var showDDLRaw string
+
+//go:embed baseline.txt
+var baselineRaw string
+
+//go:embed mark_applied.txt
+var markAppliedRaw string
+
+//go:embed mark_pending.txt
+var markPendingRaw string
+
+//go:embed history.txt
+var historyRaw string
+
+//go:embed self_upgrade.txt
+var selfUpgradeRaw string
+
 type CommandDescription struct {
 	Use   string
 	Short string
@@ -43,6 +79,54 @@ func MigrateDescription() CommandDescription {
 	return loadCommandDescription(migrateRaw)
 }
 
+func RollbackDescription() CommandDescription {
+	return loadCommandDescription(rollbackRaw)
+}
+
+func VerifyDescription() CommandDescription {
+	return loadCommandDescription(verifyRaw)
+}
+
+func LockDescription() CommandDescription {
+	return loadCommandDescription(lockRaw)
+}
+
+func LockStatusDescription() CommandDescription {
+	return loadCommandDescription(lockStatusRaw)
+}
+
+func LockReleaseDescription() CommandDescription {
+	return loadCommandDescription(lockReleaseRaw)
+}
+
+func StatusDescription() CommandDescription {
+	return loadCommandDescription(statusRaw)
+}
+
+func ShowDDLDescription() CommandDescription {
+	return loadCommandDescription(showDDLRaw)
+}
+
+func BaselineDescription() CommandDescription {
+	return loadCommandDescription(baselineRaw)
+}
+
+func MarkAppliedDescription() CommandDescription {
+	return loadCommandDescription(markAppliedRaw)
+}
+
+func MarkPendingDescription() CommandDescription {
+	return loadCommandDescription(markPendingRaw)
+}
+
+func HistoryDescription() CommandDescription {
+	return loadCommandDescription(historyRaw)
+}
+
+func SelfUpgradeDescription() CommandDescription {
+	return loadCommandDescription(selfUpgradeRaw)
+}
+
 func loadCommandDescription(s string) CommandDescription {
 	lines := strings.Split(s, unixNewLine)
 
@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/servletcloud/Andmerada/internal/migrator"
+	"github.com/servletcloud/Andmerada/internal/project"
+	"github.com/servletcloud/Andmerada/internal/source"
+)
+
+type migrateErrorPrinter struct {
+}
+
+func (m *migrateErrorPrinter) printError(err error) { //nolint:cyclop
+	if m.isCancellationError(err) {
+		m.printCancellationError(err)
+
+		return
+	}
+
+	var migratorErr *migrator.MigrateError
+
+	if !errors.As(err, &migratorErr) {
+		log.Panic(err)
+	}
+
+	switch migratorErr.ErrType {
+	case migrator.ErrTypeDBConnect:
+		m.printConnectError(migratorErr)
+	case migrator.ErrTypeCreateDDL:
+		m.printDDLError(migratorErr)
+	case migrator.ErrTypeListMigrationsOnDisk:
+		log.Printf("Failed to list migrations on disk:\n%v", migratorErr)
+	case migrator.ErrTypeScanAppliedMigrations:
+		log.Printf("Failed to scan applied migrations:\n%v", m.pgErrorToPrettyString(migratorErr))
+	case migrator.ErrTypePreValidateSources:
+		m.printLoadSourceError(migratorErr)
+		log.Println("No migrations were applied due to validation errors.")
+		log.Println("Please fix the issues and run 'andmerada migrate' again.")
+		log.Println("To skip pre-validation (e.g., in local setups), use the --skip-prevalidation flag.")
+	case migrator.ErrTypeLoadMigration:
+		m.printLoadSourceError(migratorErr)
+		log.Println("This and all subsequent migrations will not be applied.")
+		log.Println("Fix the error and run 'andmerada migrate' again.")
+	case migrator.ErrTypeApplyMigration:
+		m.printApplyError(migratorErr)
+	case migrator.ErrTypeRegisterMigration:
+		log.Printf("Failed to register migration:\n%v", m.pgErrorToPrettyString(migratorErr))
+	case migrator.ErrTypeRollbackBlocked:
+		log.Println(migratorErr.Error())
+		log.Println("No migrations were rolled back.")
+	case migrator.ErrTypeRollbackMigration:
+		m.printRollbackError(migratorErr)
+	case migrator.ErrTypeUnregisterMigration:
+		log.Printf("Failed to unregister migration:\n%v", m.pgErrorToPrettyString(migratorErr))
+	case migrator.ErrTypeChecksumDrift:
+		log.Println(migratorErr.Error())
+		log.Println("No migrations were applied because applied migrations were changed on disk.")
+		log.Println("Restore the original files, or use the --allow-drift flag to proceed anyway.")
+	case migrator.ErrTypeAcquireLock:
+		log.Printf("Failed to acquire the migrations lock:\n%v", m.pgErrorToPrettyString(migratorErr))
+		log.Println("Another andmerada process may be running. Run 'andmerada lock status' to see who holds the lock.")
+	case migrator.ErrTypeLockLost:
+		log.Println(migratorErr.Error())
+		log.Println("The remaining migrations were not applied, run 'andmerada migrate' again.")
+	case migrator.ErrTypeQueryLock:
+		log.Printf("Failed to query the migrations lock:\n%v", m.pgErrorToPrettyString(migratorErr))
+	case migrator.ErrTypeBaselineTarget:
+		log.Println(migratorErr.Error())
+		log.Println("No migrations were marked.")
+	case migrator.ErrTypeMigrateTarget:
+		log.Printf("Invalid --to value: %v", migratorErr)
+		log.Println("No migrations were applied. Run 'andmerada status' to list the available migrations.")
+	case migrator.ErrTypeRehearsal:
+		m.printRehearsalError(migratorErr)
+	case migrator.ErrTypeFilterMigrations:
+		m.printFilterError(migratorErr)
+	case migrator.ErrTypeOutOfOrder:
+		log.Println(migratorErr.Error())
+		log.Println("No migrations were applied because out_of_order is set to 'fail'.")
+		log.Println("Review the order of the migrations, or use --out-of-order=warn to apply them anyway.")
+	case migrator.ErrTypeListHistory:
+		log.Printf("Failed to list the execution history:\n%v", m.pgErrorToPrettyString(migratorErr))
+	case migrator.ErrTypeSchemaVersion:
+		m.printSchemaVersionError(migratorErr)
+	case migrator.ErrTypeCallback:
+		m.printCallbackError(migratorErr)
+	case migrator.ErrTypeIsolateSession:
+		m.printIsolateSessionError(migratorErr)
+	case migrator.ErrTypeWarning:
+		m.printWarningError(migratorErr)
+	default:
+		log.Println(migratorErr.Error())
+	}
+}
+
+func (m *migrateErrorPrinter) printCancellationError(err error) {
+	switch {
+	case errors.Is(err, context.Canceled):
+		log.Printf("Execution was cancelled by the user or due to a system timeout:\n%v", err)
+	case errors.Is(err, context.DeadlineExceeded):
+		log.Printf("Execution timed out before completing:\n%v", err)
+	}
+}
+
+func (m *migrateErrorPrinter) isCancellationError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+func (m *migrateErrorPrinter) printConnectError(err *migrator.MigrateError) {
+	var parseConfigErr *pgconn.ParseConfigError
+
+	if errors.As(err, &parseConfigErr) {
+		helpURL := "https://www.postgresql.org/docs/current/libpq-connect.html#LIBPQ-CONNSTRING"
+		log.Printf("Invalid database URL: %v\n\nRead more at %v", parseConfigErr, helpURL)
+	} else {
+		log.Printf("Failed to connect to the database: %v", err)
+	}
+}
+
+func (m *migrateErrorPrinter) printDDLError(err *migrator.MigrateError) {
+	log.Println(m.pgErrorToPrettyString(err))
+	log.Println("Failed to create auxiliary tables for managing migrations.")
+	log.Println("Run 'andmerada show-ddl' to view the DDL SQL if you need to execute it manually.")
+}
+
+func (m *migrateErrorPrinter) printSchemaVersionError(err *migrator.MigrateError) {
+	log.Printf("Cannot use the bookkeeping tables: %v.", err)
+
+	if tooNewErr := new(migrator.SchemaTooNewError); errors.As(err, &tooNewErr) {
+		log.Println("They were upgraded by a newer release of andmerada. Upgrade andmerada to continue.")
+
+		return
+	}
+
+	log.Println("Run 'andmerada self-upgrade' to upgrade them, or set 'self_upgrade: auto' in andmerada.yml.")
+}
+
+func (m *migrateErrorPrinter) printCallbackError(err *migrator.MigrateError) {
+	var callbackErr *migrator.CallbackError
+
+	if errors.As(err, &callbackErr) {
+		log.Printf("Callback %s failed:\n%v", callbackErr.Callback, m.pgErrorToPrettyString(callbackErr.Cause))
+	} else {
+		log.Println(err.Error())
+	}
+
+	log.Printf("Fix the SQL files in the %q directory and run 'andmerada migrate' again.", project.CallbacksDirName)
+}
+
+func (m *migrateErrorPrinter) printIsolateSessionError(err *migrator.MigrateError) {
+	var isolateErr *migrator.IsolateSessionError
+
+	if errors.As(err, &isolateErr) {
+		log.Printf("Migration %q was applied, but its session could not be isolated:\n%v",
+			isolateErr.Name, m.pgErrorToPrettyString(isolateErr.Cause))
+	} else {
+		log.Println(err.Error())
+	}
+
+	log.Println("The migration was not registered and subsequent migrations were not applied.")
+	log.Println("Verify its changes, then register it with 'andmerada mark-applied' or revert them manually.")
+}
+
+func (m *migrateErrorPrinter) printWarningError(err *migrator.MigrateError) {
+	var warningErr *migrator.WarningError
+
+	if !errors.As(err, &warningErr) {
+		log.Println(err.Error())
+
+		return
+	}
+
+	log.Printf("Migration %q raised warnings and fail_on_warning is set:", warningErr.Name)
+
+	for _, warning := range warningErr.Warnings {
+		log.Printf("  %s: %s", warning.Severity, warning.Message)
+	}
+
+	if warningErr.RolledBack {
+		log.Println("The migration was rolled back, and the ones after it were not applied.")
+	} else {
+		log.Println("The migration stays applied, because with `transaction: none` it was committed before the " +
+			"warnings were checked. The ones after it were not applied.")
+	}
+
+	log.Println("Fix the warnings, or set fail_on_warning to false in andmerada.yml.")
+}
+
+func (m *migrateErrorPrinter) printLoadSourceError(err *migrator.MigrateError) {
+	var loadSourceErr *migrator.LoadSourceError
+
+	if errors.As(err, &loadSourceErr) {
+		log.Printf("Failed to load or parse migration from disk %q:\n%v", loadSourceErr.Name, err)
+	} else {
+		log.Println(err.Error())
+	}
+
+	log.Println()
+}
+
+func (m *migrateErrorPrinter) printFilterError(err *migrator.MigrateError) {
+	var compileErr *source.CompileFilterError
+
+	var runErr *source.RunFilterError
+
+	switch {
+	case errors.As(err, &compileErr):
+		log.Printf("Invalid --filter expression %q:\n%v", compileErr.Expression, compileErr.Err)
+	case errors.As(err, &runErr):
+		log.Printf("The --filter expression %q failed for migration %v:\n%v", runErr.Expression, runErr.ID, runErr.Err)
+	default:
+		log.Println(err.Error())
+	}
+
+	log.Println("No migrations were applied.")
+	log.Println("The expression may use id, sid, createdAt, age, ageDays and now(). age and ageDays are nil for " +
+		"migrations with timestamps in the future.")
+}
+
+func (m *migrateErrorPrinter) printRehearsalError(err *migrator.MigrateError) {
+	var rehearsalErr *migrator.RehearsalFailedError
+
+	if !errors.As(err, &rehearsalErr) {
+		log.Printf("Failed to rehearse migrations:\n%v", m.pgErrorToPrettyString(err))
+
+		return
+	}
+
+	for _, rehearsal := range rehearsalErr.Failed {
+		log.Printf("Rehearsal of migration %q failed:\n%v", rehearsal.Name, m.pgErrorToPrettyString(rehearsal.Err))
+	}
+
+	log.Printf("%d of %d rehearsed migration(s) failed. All changes were rolled back.",
+		len(rehearsalErr.Failed), rehearsalErr.Total)
+}
+
+func (m *migrateErrorPrinter) printApplyError(err *migrator.MigrateError) {
+	var applyError *migrator.ApplyMigrationError
+
+	msg := m.pgErrorToPrettyString(err)
+
+	if errors.As(err, &applyError) {
+		log.Printf("Failed to apply migration %q:\n%v", applyError.Name, msg)
+	} else {
+		log.Printf("Failed to apply a migration:\n%v", msg)
+	}
+}
+
+func (m *migrateErrorPrinter) printRollbackError(err *migrator.MigrateError) {
+	var rollbackError *migrator.RollbackMigrationError
+
+	msg := m.pgErrorToPrettyString(err)
+
+	if errors.As(err, &rollbackError) {
+		log.Printf("Failed to roll back migration %v %q:\n%v", rollbackError.ID, rollbackError.Name, msg)
+	} else {
+		log.Printf("Failed to roll back a migration:\n%v", msg)
+	}
+}
+
+func (m *migrateErrorPrinter) pgErrorToPrettyString(err error) string {
+	var execSQLErr *migrator.ExecSQLError
+
+	var pgError *pgconn.PgError
+
+	if errors.As(err, &execSQLErr) && errors.As(err, &pgError) {
+		return newPgErrorTranslator(err).prettyPrint(pgError, execSQLErr.SQL)
+	}
+
+	return err.Error()
+}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/servletcloud/Andmerada/internal/cmd/descriptions"
+	"github.com/servletcloud/Andmerada/internal/migrator"
+	"github.com/spf13/cobra"
+)
+
+func historyCommand() *cobra.Command {
+	description := descriptions.HistoryDescription()
+	history := historyCmdRunner{}
+
+	//nolint:exhaustruct
+	command := &cobra.Command{
+		Use:   description.Use,
+		Short: description.Short,
+		Long:  description.Long,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			history.Run(cmd)
+		},
+		Example: `andmerada history --id 20241225112129 --outcome failed --limit 10`,
+	}
+
+	addDatabaseURLFlag(command)
+
+	command.Flags().String("id", "", "Shows only the attempts of the migration with the given ID.")
+
+	command.Flags().String("outcome", "", "Shows only the attempts with the given outcome: succeeded or failed.")
+
+	command.Flags().Uint32("limit", 0, "Shows only the given number of the latest attempts. 0 shows all of them.")
+
+	command.Flags().Bool("json", false, "Prints the history as JSON.")
+
+	return command
+}
+
+type historyCmdRunner struct {
+	migrateErrorPrinter
+}
+
+func (h *historyCmdRunner) Run(cmd *cobra.Command) {
+	project, environment := mustLoadProjectEnvironment(cmd)
+
+	databaseURL := mustGetDatabaseURL(cmd, environment)
+
+	migrationID := mustGetMigrationIDFlag(cmd, "id")
+
+	outcomeStr, _ := cmd.Flags().GetString("outcome")
+	outcome := migrator.HistoryOutcome(outcomeStr)
+
+	if outcome != "" && !outcome.IsValid() {
+		log.Fatalf("Invalid --outcome value %q. Expected 'succeeded' or 'failed'.", outcomeStr)
+	}
+
+	limit, _ := cmd.Flags().GetUint32("limit")
+
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	options := migrator.HistoryOptions{
+		DatabaseURL: databaseURL,
+		Project:     project,
+		Filter: migrator.HistoryFilter{
+			MigrationID: migrationID,
+			Outcome:     outcome,
+			Limit:       int(limit),
+		},
+	}
+
+	attempts, err := migrator.ListHistory(cmd.Context(), options)
+	if err != nil {
+		h.printError(err)
+		os.Exit(exitCodeCriticalFailure)
+	}
+
+	if asJSON {
+		h.printJSON(cmd.OutOrStdout(), attempts)
+	} else {
+		h.printTable(cmd.OutOrStdout(), attempts)
+	}
+}
+
+func (h *historyCmdRunner) printJSON(out io.Writer, attempts []migrator.Attempt) {
+	if attempts == nil {
+		attempts = []migrator.Attempt{}
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(attempts); err != nil {
+		log.Panic(err)
+	}
+}
+
+func (h *historyCmdRunner) printTable(out io.Writer, attempts []migrator.Attempt) {
+	if len(attempts) == 0 {
+		log.Println("No attempts found in the execution history.")
+
+		return
+	}
+
+	const padding = 2
+
+	writer := tabwriter.NewWriter(out, 0, 0, padding, ' ', 0)
+
+	fmt.Fprintln(writer, "ID\tNAME\tOPERATION\tOUTCOME\tSTARTED AT\tDURATION MS\tSQLSTATE\tUSER\tHOST\tCOMMIT")
+
+	for _, attempt := range attempts {
+		fmt.Fprintf(writer, "%v\t%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\n",
+			attempt.MigrationID,
+			attempt.Name,
+			attempt.Operation,
+			attempt.Outcome,
+			attempt.StartedAt.UTC().Format(time.RFC3339),
+			attempt.FinishedAt.Sub(attempt.StartedAt).Milliseconds(),
+			orDash(attempt.SQLState),
+			attempt.DatabaseUser,
+			orDash(attempt.ClientHostname),
+			orDash(shortCommit(attempt.GitCommit)),
+		)
+	}
+
+	if err := writer.Flush(); err != nil {
+		log.Panic(err)
+	}
+
+	for _, attempt := range attempts {
+		if attempt.Outcome == migrator.OutcomeFailed && attempt.Message != "" {
+			log.Printf(" %v %s failed: %s", attempt.MigrationID, attempt.Operation, attempt.Message)
+		}
+	}
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
+
+func shortCommit(commit string) string {
+	const shortCommitLength = 12
+
+	if len(commit) <= shortCommitLength {
+		return commit
+	}
+
+	return commit[:shortCommitLength]
+}
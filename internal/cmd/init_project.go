@@ -3,6 +3,7 @@ package cmd
 import (
 	"errors"
 	"log"
+	"path/filepath"
 
 	"github.com/servletcloud/Andmerada/internal/cmd/descriptions"
 	"github.com/servletcloud/Andmerada/internal/osutil"
@@ -20,17 +21,23 @@ func initProjectCommand() *cobra.Command {
 		Short: description.Short,
 		Long:  description.Long,
 		Args:  cobra.MaximumNArgs(1),
-		Run: func(_ *cobra.Command, args []string) {
-			var projectDir string
+		Run: func(cmd *cobra.Command, args []string) {
+			projectDir, _ := cmd.Flags().GetString("project-dir")
+
 			if len(args) > 0 {
 				projectDir = args[0]
-			} else {
+			} else if projectDir == "" {
 				projectDir = osutil.GetwdOrPanic()
 			}
 
-			if err := project.Initialize(projectDir); err != nil {
+			projectDir, err := filepath.Abs(projectDir)
+			if err != nil {
+				log.Fatalf("Cannot resolve the project directory %v: %v", projectDir, err)
+			}
+
+			if err = project.Initialize(projectDir); err != nil {
 				if errors.Is(err, project.ErrConfigFileAlreadyExists) {
-					log.Fatalln(resources.MsgErrProjectExists())
+					log.Fatalln(resources.MsgErrProjectExists(project.ConfigPath(projectDir)))
 				}
 				log.Panic(err)
 			}
@@ -9,7 +9,7 @@ import (
 	"github.com/dustin/go-humanize/english"
 	"github.com/servletcloud/Andmerada/internal/cmd/descriptions"
 	"github.com/servletcloud/Andmerada/internal/linter"
-	"github.com/servletcloud/Andmerada/internal/osutil"
+	"github.com/servletcloud/Andmerada/internal/placeholder"
 	"github.com/servletcloud/Andmerada/internal/resources"
 	"github.com/servletcloud/Andmerada/internal/source"
 	"github.com/spf13/cobra"
@@ -22,24 +22,27 @@ const (
 func lintCommand() *cobra.Command {
 	description := descriptions.LintDescription()
 
-	return &cobra.Command{ //nolint:exhaustruct
+	command := &cobra.Command{ //nolint:exhaustruct
 		Use:   description.Use,
 		Short: description.Short,
 		Long:  description.Long,
-		Run: func(_ *cobra.Command, _ []string) {
-			currentDir := osutil.GetwdOrPanic()
+		Run: func(cmd *cobra.Command, _ []string) {
+			placeholders := mustGetPlaceholders(cmd)
 
-			ensureProjectInitialized(currentDir)
+			project, _ := mustLoadProjectEnvironment(cmd)
 
 			log.Println("Validating the migration files, please, wait...")
 			log.Println()
 
 			config := linter.Configuration{
-				ProjectDir:      currentDir,
+				ProjectDir:      project.Dir,
 				MaxSQLFileSize:  MaxSQLFileSizeBytes,
 				NowID:           source.NewIDFromNow(),
 				UpSQLTemplate:   resources.TemplateUpSQL(),
 				DownSQLTemplate: resources.TemplateDownSQL(),
+				Transaction:     project.Configuration.Transaction,
+				Isolation:       project.Configuration.Isolation,
+				Placeholders:    placeholder.Merge(project.Configuration.Placeholders, placeholders),
 			}
 			report := new(linter.Report)
 			if err := linter.Run(config, report); err != nil {
@@ -53,6 +56,10 @@ func lintCommand() *cobra.Command {
 			}
 		},
 	}
+
+	addSetFlag(command)
+
+	return command
 }
 
 func printLintReport(report *linter.Report) {
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/servletcloud/Andmerada/internal/cmd/descriptions"
+	"github.com/servletcloud/Andmerada/internal/migrator"
+	"github.com/spf13/cobra"
+)
+
+type lockFunc func(ctx context.Context, options migrator.LockOptions) ([]migrator.LockHolder, error)
+
+func lockCommand() *cobra.Command {
+	description := descriptions.LockDescription()
+
+	//nolint:exhaustruct
+	command := &cobra.Command{
+		Use:   description.Use,
+		Short: description.Short,
+		Long:  description.Long,
+		Args:  cobra.NoArgs,
+	}
+
+	command.AddCommand(
+		lockStatusCommand(),
+		lockReleaseCommand(),
+	)
+
+	return command
+}
+
+func lockStatusCommand() *cobra.Command {
+	description := descriptions.LockStatusDescription()
+	lock := lockCmdRunner{}
+
+	//nolint:exhaustruct
+	command := &cobra.Command{
+		Use:   description.Use,
+		Short: description.Short,
+		Long:  description.Long,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			holders := lock.Run(cmd, migrator.LockStatus, false)
+
+			if len(holders) == 0 {
+				log.Println("The migrations lock is free.")
+
+				return
+			}
+
+			log.Println("The migrations lock is held by:")
+			lock.printHolders(holders)
+		},
+	}
+
+	addDatabaseURLFlag(command)
+
+	return command
+}
+
+func lockReleaseCommand() *cobra.Command {
+	description := descriptions.LockReleaseDescription()
+	lock := lockCmdRunner{}
+
+	//nolint:exhaustruct
+	command := &cobra.Command{
+		Use:   description.Use,
+		Short: description.Short,
+		Long:  description.Long,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			holders := lock.Run(cmd, migrator.ReleaseLock, true)
+
+			if len(holders) == 0 {
+				log.Println("The migrations lock is free, nothing to release.")
+
+				return
+			}
+
+			log.Println("Released the migrations lock by terminating:")
+			lock.printHolders(holders)
+		},
+	}
+
+	addDatabaseURLFlag(command)
+
+	return command
+}
+
+type lockCmdRunner struct {
+	migrateErrorPrinter
+}
+
+// Run executes the lock function. When it writes, a protected environment needs confirmation.
+func (l *lockCmdRunner) Run(cmd *cobra.Command, lockFunc lockFunc, writes bool) []migrator.LockHolder {
+	project, environment := mustLoadProjectEnvironment(cmd)
+
+	databaseURL := mustGetDatabaseURL(cmd, environment)
+
+	if writes {
+		mustConfirmWrite(cmd, environment)
+	}
+
+	options := migrator.LockOptions{
+		DatabaseURL: databaseURL,
+		Project:     project,
+	}
+
+	holders, err := lockFunc(cmd.Context(), options)
+	if err != nil {
+		l.printError(err)
+		os.Exit(exitCodeCriticalFailure)
+	}
+
+	return holders
+}
+
+func (l *lockCmdRunner) printHolders(holders []migrator.LockHolder) {
+	for _, holder := range holders {
+		log.Printf("  - PID %d, application %q, user %q, client %q, connected since %v",
+			holder.PID,
+			holder.ApplicationName,
+			holder.UserName,
+			holder.ClientAddr,
+			holder.BackendStart.Format(time.RFC3339),
+		)
+	}
+}
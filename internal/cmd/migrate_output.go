@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/servletcloud/Andmerada/internal/migrator"
+	"github.com/servletcloud/Andmerada/internal/osutil"
+	"github.com/servletcloud/Andmerada/internal/source"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	outputFormatJSON = "json"
+	outputFormatYAML = "yaml"
+
+	documentStatusSucceeded = "succeeded"
+	documentStatusFailed    = "failed"
+)
+
+// migrateDocument is the machine-readable report of 'andmerada migrate --output'.
+type migrateDocument struct {
+	Status          string              `json:"status"                      yaml:"status"`
+	DryRun          bool                `json:"dry_run"                     yaml:"dry_run"`
+	Rehearse        bool                `json:"rehearse"                    yaml:"rehearse"`
+	PendingCount    int                 `json:"pending_count"               yaml:"pending_count"`
+	LatestAppliedID *source.ID          `json:"latest_applied_id,omitempty" yaml:"latest_applied_id,omitempty"`
+	OutOfOrderIDs   []source.ID         `json:"out_of_order_ids"            yaml:"out_of_order_ids"`
+	SkippedIDs      []source.ID         `json:"skipped_ids"                 yaml:"skipped_ids"`
+	Migrations      []migrationDocument `json:"migrations"                  yaml:"migrations"`
+	Error           *errorDocument      `json:"error,omitempty"             yaml:"error,omitempty"`
+}
+
+type migrationDocument struct {
+	ID         source.ID        `json:"id"                yaml:"id"`
+	Name       string           `json:"name"              yaml:"name"`
+	Status     string           `json:"status"            yaml:"status"`
+	DurationMs int64            `json:"duration_ms"       yaml:"duration_ms"`
+	Notices    []noticeDocument `json:"notices,omitempty" yaml:"notices,omitempty"`
+	Error      *errorDocument   `json:"error,omitempty"   yaml:"error,omitempty"`
+}
+
+// noticeDocument is a NOTICE, WARNING or other message that PostgreSQL sent while the migration ran.
+type noticeDocument struct {
+	Severity string `json:"severity"           yaml:"severity"`
+	SQLState string `json:"sqlstate,omitempty" yaml:"sqlstate,omitempty"`
+	Message  string `json:"message"            yaml:"message"`
+	Detail   string `json:"detail,omitempty"   yaml:"detail,omitempty"`
+	Hint     string `json:"hint,omitempty"     yaml:"hint,omitempty"`
+}
+
+// errorDocument carries the fields that pgErrorTranslator prints for PostgreSQL errors.
+// Line and Column point into the executed SQL, or into the migration file when Statement is set
+// by the statement execution mode. Pretty is the full human-readable text.
+type errorDocument struct {
+	Message    string `json:"message"               yaml:"message"`
+	SQLState   string `json:"sqlstate,omitempty"    yaml:"sqlstate,omitempty"`
+	Severity   string `json:"severity,omitempty"    yaml:"severity,omitempty"`
+	Detail     string `json:"detail,omitempty"      yaml:"detail,omitempty"`
+	Hint       string `json:"hint,omitempty"        yaml:"hint,omitempty"`
+	Where      string `json:"where,omitempty"       yaml:"where,omitempty"`
+	Position   int    `json:"position,omitempty"    yaml:"position,omitempty"`
+	Statement  int    `json:"statement,omitempty"   yaml:"statement,omitempty"`
+	Line       int    `json:"line,omitempty"        yaml:"line,omitempty"`
+	Column     int    `json:"column,omitempty"      yaml:"column,omitempty"`
+	Schema     string `json:"schema,omitempty"      yaml:"schema,omitempty"`
+	Table      string `json:"table,omitempty"       yaml:"table,omitempty"`
+	ColumnName string `json:"column_name,omitempty" yaml:"column_name,omitempty"`
+	Constraint string `json:"constraint,omitempty"  yaml:"constraint,omitempty"`
+	Pretty     string `json:"pretty,omitempty"      yaml:"pretty,omitempty"`
+}
+
+func newMigrateDocument(options *migrator.ApplyOptions, report *migrator.Report, err error) migrateDocument {
+	document := migrateDocument{
+		Status:          documentStatusSucceeded,
+		DryRun:          options.DryRun,
+		Rehearse:        options.Rehearse,
+		PendingCount:    report.PendingCount,
+		LatestAppliedID: nil,
+		OutOfOrderIDs:   nonNil(report.OutOfOrderIDs),
+		SkippedIDs:      nonNil(report.SkippedIDs),
+		Migrations:      make([]migrationDocument, 0, len(report.Entries)),
+		Error:           nil,
+	}
+
+	if report.LatestAppliedID != source.EmptyMigrationID {
+		document.LatestAppliedID = &report.LatestAppliedID
+	}
+
+	for _, entry := range report.Entries {
+		migration := migrationDocument{
+			ID:         entry.ID,
+			Name:       entry.Name,
+			Status:     string(entry.Status),
+			DurationMs: entry.Duration.Milliseconds(),
+			Notices:    newNoticeDocuments(entry.Notices),
+			Error:      nil,
+		}
+
+		if entry.Err != nil {
+			migration.Error = newErrorDocument(entry.Err)
+		}
+
+		document.Migrations = append(document.Migrations, migration)
+	}
+
+	if err != nil {
+		document.Status = documentStatusFailed
+		document.Error = newErrorDocument(err)
+	}
+
+	return document
+}
+
+func newNoticeDocuments(notices []migrator.Notice) []noticeDocument {
+	documents := make([]noticeDocument, 0, len(notices))
+
+	for _, notice := range notices {
+		documents = append(documents, noticeDocument{
+			Severity: notice.Severity,
+			SQLState: notice.SQLState,
+			Message:  notice.Message,
+			Detail:   notice.Detail,
+			Hint:     notice.Hint,
+		})
+	}
+
+	return documents
+}
+
+func newErrorDocument(err error) *errorDocument {
+	document := &errorDocument{Message: err.Error()} //nolint:exhaustruct
+
+	var pgError *pgconn.PgError
+
+	if !errors.As(err, &pgError) {
+		return document
+	}
+
+	document.Message = pgError.Message
+	document.SQLState = pgError.Code
+	document.Severity = pgError.Severity
+	document.Detail = pgError.Detail
+	document.Hint = pgError.Hint
+	document.Where = pgError.Where
+	document.Position = int(pgError.Position)
+	document.Schema = pgError.SchemaName
+	document.Table = pgError.TableName
+	document.ColumnName = pgError.ColumnName
+	document.Constraint = pgError.ConstraintName
+
+	translator := newPgErrorTranslator(err)
+	sql := ""
+
+	var execSQLErr *migrator.ExecSQLError
+
+	if errors.As(err, &execSQLErr) {
+		sql = execSQLErr.SQL
+	}
+
+	if document.Position > 0 && document.Position <= len(sql) {
+		document.Line, document.Column, _ = translator.highlightSQLPosition(sql, document.Position)
+		document.Line = translator.fileLine(document.Line)
+	}
+
+	if statement := translator.statement; statement != nil {
+		document.Statement = statement.Number
+		document.Line = cmp.Or(document.Line, statement.Line)
+	}
+
+	document.Pretty = translator.prettyPrint(pgError, sql)
+
+	return document
+}
+
+func writeMigrateDocument(out io.Writer, format string, outputFile string, document *migrateDocument) error {
+	var (
+		content []byte
+		err     error
+	)
+
+	switch format {
+	case outputFormatJSON:
+		content, err = json.MarshalIndent(document, "", "  ")
+		content = append(content, '\n')
+	case outputFormatYAML:
+		content, err = yaml.Marshal(document)
+	default:
+		err = fmt.Errorf("unsupported output format %q", format)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if outputFile == "" {
+		_, err = out.Write(content)
+
+		return err //nolint:wrapcheck
+	}
+
+	return os.WriteFile(outputFile, content, osutil.FilePerm0644) //nolint:wrapcheck
+}
+
+func nonNil[T any](values []T) []T {
+	if values == nil {
+		return []T{}
+	}
+
+	return values
+}
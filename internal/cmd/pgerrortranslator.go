@@ -1,13 +1,25 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/servletcloud/Andmerada/internal/migrator"
 )
 
 type pgErrorTranslator struct {
+	// statement locates the failed statement in its file when the migration runs statement by statement.
+	statement *migrator.StatementError
+}
+
+func newPgErrorTranslator(err error) *pgErrorTranslator {
+	translator := &pgErrorTranslator{statement: nil}
+
+	errors.As(err, &translator.statement)
+
+	return translator
 }
 
 func (translator *pgErrorTranslator) prettyPrint(err *pgconn.PgError, sql string) string {
@@ -26,9 +38,13 @@ func (translator *pgErrorTranslator) prettyPrint(err *pgconn.PgError, sql string
 	translator.writeKv(&sb, "Hint", err.Hint)
 	translator.writeKv(&sb, "Detail", err.Detail)
 
+	if statement := translator.statement; statement != nil {
+		sb.WriteString(fmt.Sprintf("Statement: %d of %d, file line %d\n", statement.Number, statement.Count, statement.Line))
+	}
+
 	if err.Position > 0 && int(err.Position) <= len(sql) && sql != "" {
 		lineNumber, colNumber, highlightedSQL := translator.highlightSQLPosition(sql, int(err.Position))
-		sb.WriteString(fmt.Sprintf("\nError Location at line %d, column %d:\n", lineNumber, colNumber))
+		sb.WriteString(fmt.Sprintf("\nError Location at %s, column %d:\n", translator.location(lineNumber), colNumber))
 		sb.WriteString(highlightedSQL)
 	}
 
@@ -46,6 +62,23 @@ func (translator *pgErrorTranslator) prettyPrint(err *pgconn.PgError, sql string
 	return sb.String()
 }
 
+// fileLine converts a line of the executed SQL into the line of the migration file.
+func (translator *pgErrorTranslator) fileLine(line int) int {
+	if translator.statement == nil {
+		return line
+	}
+
+	return translator.statement.Line + line - 1
+}
+
+func (translator *pgErrorTranslator) location(line int) string {
+	if translator.statement == nil {
+		return fmt.Sprintf("line %d", line)
+	}
+
+	return fmt.Sprintf("statement %d, file line %d", translator.statement.Number, translator.fileLine(line))
+}
+
 func (translator *pgErrorTranslator) writeKv(sb *strings.Builder, key, value string) {
 	if value == "" {
 		return
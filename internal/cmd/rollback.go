@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"errors"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/servletcloud/Andmerada/internal/cmd/descriptions"
+	"github.com/servletcloud/Andmerada/internal/migrator"
+	"github.com/servletcloud/Andmerada/internal/source"
+	"github.com/spf13/cobra"
+)
+
+const (
+	downSQLSourceDatabase = "db"
+	downSQLSourceDisk     = "disk"
+)
+
+func rollbackCommand() *cobra.Command {
+	description := descriptions.RollbackDescription()
+	rollback := rollbackCmdRunner{}
+
+	//nolint:exhaustruct
+	command := &cobra.Command{
+		Use:   description.Use,
+		Short: description.Short,
+		Long:  description.Long,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			rollback.Run(cmd)
+		},
+		Example: `andmerada rollback --count=2
+andmerada rollback --to=20241225112129 --dry-run`,
+	}
+
+	addDatabaseURLFlag(command)
+
+	command.Flags().Uint32(
+		"count",
+		0,
+		"Number of applied migrations to roll back. Defaults to 1 unless --to is specified.",
+	)
+
+	command.Flags().String(
+		"to",
+		"",
+		"Rolls back every migration applied after the given ID. The migration with this ID stays applied.",
+	)
+
+	command.Flags().String(
+		"down-sql-source",
+		downSQLSourceDatabase,
+		"Where to take the down SQL from: 'db' (stored in the migrations table when applied) or 'disk'.",
+	)
+
+	command.Flags().Bool(
+		"dry-run",
+		strings.ToLower(os.Getenv("DRY_RUN")) == "true",
+		"Prints the down SQL that would be executed without changing the database. "+
+			"Defaults to the DRY_RUN environment variable.",
+	)
+
+	addLockTimeoutFlag(command)
+
+	addSetFlag(command)
+
+	return command
+}
+
+type rollbackCmdRunner struct {
+	migrateErrorPrinter
+}
+
+func (r *rollbackCmdRunner) Run(cmd *cobra.Command) {
+	project, environment := mustLoadProjectEnvironment(cmd)
+
+	databaseURL := mustGetDatabaseURL(cmd, environment)
+
+	count, _ := cmd.Flags().GetUint32("count")
+
+	toID := mustGetMigrationIDFlag(cmd, "to")
+
+	if count == 0 && toID == source.EmptyMigrationID {
+		count = 1
+	}
+
+	dryRun := getBoolFlag(cmd, "dry-run", environment.DryRun)
+
+	lockTimeout, _ := cmd.Flags().GetDuration("lock-timeout")
+
+	placeholders := mustGetPlaceholders(cmd)
+
+	if !dryRun {
+		mustConfirmWrite(cmd, environment)
+	}
+
+	options := migrator.RollbackOptions{
+		MaxSQLFileSize: MaxSQLFileSizeBytes,
+		DatabaseURL:    databaseURL,
+		Project:        project,
+		Count:          int(count),
+		ToID:           toID,
+		DownSQLSource:  r.mustGetDownSQLSource(cmd),
+		DryRun:         dryRun,
+		LockTimeout:    lockTimeout,
+		Placeholders:   placeholders,
+	}
+	report := migrator.RollbackReport{TargetCount: 0}
+
+	if err := migrator.Rollback(cmd.Context(), options, &report); err != nil {
+		r.printError(err)
+		os.Exit(exitCodeCriticalFailure)
+	}
+
+	r.printReport(&report)
+}
+
+func (r *rollbackCmdRunner) mustGetDownSQLSource(cmd *cobra.Command) migrator.DownSQLSource {
+	value, _ := cmd.Flags().GetString("down-sql-source")
+
+	switch value {
+	case downSQLSourceDatabase:
+		return migrator.DownSQLFromDatabase
+	case downSQLSourceDisk:
+		return migrator.DownSQLFromDisk
+	default:
+		log.Fatalf("Invalid --down-sql-source value %q. Expected 'db' or 'disk'.", value)
+	}
+
+	return migrator.DownSQLFromDatabase
+}
+
+func (r *rollbackCmdRunner) printError(err error) {
+	var migratorErr *migrator.MigrateError
+
+	if errors.As(err, &migratorErr) && migratorErr.ErrType == migrator.ErrTypeLoadMigration {
+		r.printLoadSourceError(migratorErr)
+		log.Println("No migrations were rolled back.")
+
+		return
+	}
+
+	r.migrateErrorPrinter.printError(err)
+}
+
+func (r *rollbackCmdRunner) printReport(report *migrator.RollbackReport) {
+	if report.TargetCount == 0 {
+		log.Println("No migrations to roll back.")
+	}
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/servletcloud/Andmerada/internal/cmd/descriptions"
+	"github.com/servletcloud/Andmerada/internal/migrator"
+	"github.com/spf13/cobra"
+)
+
+func selfUpgradeCommand() *cobra.Command {
+	description := descriptions.SelfUpgradeDescription()
+	selfUpgrade := selfUpgradeCmdRunner{}
+
+	//nolint:exhaustruct
+	command := &cobra.Command{
+		Use:   description.Use,
+		Short: description.Short,
+		Long:  description.Long,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			selfUpgrade.Run(cmd)
+		},
+		Example: `andmerada self-upgrade --dry-run`,
+	}
+
+	addDatabaseURLFlag(command)
+	addLockTimeoutFlag(command)
+
+	command.Flags().Bool("dry-run", false, "Prints the current and the target version without upgrading.")
+
+	return command
+}
+
+type selfUpgradeCmdRunner struct {
+	migrateErrorPrinter
+}
+
+func (s *selfUpgradeCmdRunner) Run(cmd *cobra.Command) {
+	project, environment := mustLoadProjectEnvironment(cmd)
+
+	databaseURL := mustGetDatabaseURL(cmd, environment)
+
+	lockTimeout, _ := cmd.Flags().GetDuration("lock-timeout")
+
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	if !dryRun {
+		mustConfirmWrite(cmd, environment)
+	}
+
+	options := migrator.SelfUpgradeOptions{
+		DatabaseURL: databaseURL,
+		Project:     project,
+		LockTimeout: lockTimeout,
+		DryRun:      dryRun,
+	}
+	report := migrator.SelfUpgradeReport{FromVersion: 0, ToVersion: 0}
+
+	if err := migrator.SelfUpgrade(cmd.Context(), options, &report); err != nil {
+		s.printError(err)
+		os.Exit(exitCodeCriticalFailure)
+	}
+
+	switch {
+	case report.FromVersion == report.ToVersion:
+		log.Printf("The bookkeeping schema is up to date at version %d.", report.ToVersion)
+	case dryRun:
+		log.Printf("Would upgrade the bookkeeping schema from version %d to %d.", report.FromVersion, report.ToVersion)
+	default:
+		log.Printf("Upgraded the bookkeeping schema from version %d to %d.", report.FromVersion, report.ToVersion)
+	}
+}
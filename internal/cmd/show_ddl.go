@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/servletcloud/Andmerada/internal/cmd/descriptions"
+	"github.com/servletcloud/Andmerada/internal/migrator/sqlres"
+	"github.com/spf13/cobra"
+)
+
+func showDDLCommand() *cobra.Command {
+	description := descriptions.ShowDDLDescription()
+
+	//nolint:exhaustruct
+	command := &cobra.Command{
+		Use:   description.Use,
+		Short: description.Short,
+		Long:  description.Long,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			project, _ := mustLoadProjectEnvironment(cmd)
+			tableName := project.Configuration.MigrationsTableName
+			out := cmd.OutOrStdout()
+
+			if project.Configuration.CreateSchema {
+				fmt.Fprint(out, sqlres.SchemaDDL(tableName))
+			}
+
+			fmt.Fprint(out, sqlres.DDL(tableName))
+
+			if role, _ := cmd.Flags().GetString("grant-to"); role != "" {
+				fmt.Fprintln(out)
+				fmt.Fprint(out, sqlres.Grants(tableName, role))
+			}
+		},
+		Example: `andmerada show-ddl --grant-to=app_migrator > andmerada_ddl.sql`,
+	}
+
+	command.Flags().String(
+		"grant-to",
+		"",
+		"Appends the GRANT statements that the given database role needs to run migrations.",
+	)
+
+	return command
+}
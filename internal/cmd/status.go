@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/servletcloud/Andmerada/internal/cmd/descriptions"
+	"github.com/servletcloud/Andmerada/internal/migrator"
+	"github.com/spf13/cobra"
+)
+
+const (
+	exitCodePendingMigrations = 1
+)
+
+func statusCommand() *cobra.Command {
+	description := descriptions.StatusDescription()
+	status := statusCmdRunner{}
+
+	//nolint:exhaustruct
+	command := &cobra.Command{
+		Use:   description.Use,
+		Short: description.Short,
+		Long:  description.Long,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			status.Run(cmd)
+		},
+		Example: `andmerada status --json --fail-on-pending`,
+	}
+
+	addDatabaseURLFlag(command)
+
+	command.Flags().Bool("json", false, "Prints the status as JSON.")
+
+	command.Flags().Bool(
+		"fail-on-pending",
+		false,
+		"Exits with code 1 if there are pending or outdated migrations. Useful for gating CI pipelines.",
+	)
+
+	return command
+}
+
+type statusCmdRunner struct {
+	migrateErrorPrinter
+}
+
+func (s *statusCmdRunner) Run(cmd *cobra.Command) {
+	project, environment := mustLoadProjectEnvironment(cmd)
+
+	databaseURL := mustGetDatabaseURL(cmd, environment)
+
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	failOnPending, _ := cmd.Flags().GetBool("fail-on-pending")
+
+	options := migrator.StatusOptions{
+		MaxSQLFileSize: MaxSQLFileSizeBytes,
+		DatabaseURL:    databaseURL,
+		Project:        project,
+	}
+	report := migrator.StatusReport{Entries: nil}
+
+	if err := migrator.Status(cmd.Context(), options, &report); err != nil {
+		s.printError(err)
+		os.Exit(exitCodeCriticalFailure)
+	}
+
+	if asJSON {
+		s.printJSON(cmd.OutOrStdout(), &report)
+	} else {
+		s.printTable(cmd.OutOrStdout(), &report)
+	}
+
+	if failOnPending && report.CountOf(migrator.StatePending)+report.CountOf(migrator.StateOutdated) > 0 {
+		os.Exit(exitCodePendingMigrations)
+	}
+}
+
+func (s *statusCmdRunner) printJSON(out io.Writer, report *migrator.StatusReport) {
+	entries := report.Entries
+	if entries == nil {
+		entries = []migrator.StatusEntry{}
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(entries); err != nil {
+		log.Panic(err)
+	}
+}
+
+func (s *statusCmdRunner) printTable(out io.Writer, report *migrator.StatusReport) {
+	if len(report.Entries) == 0 {
+		log.Println(`No migrations found. To add one, run:
+andmerada create-migration "Add users table"`)
+
+		return
+	}
+
+	const padding = 2
+
+	writer := tabwriter.NewWriter(out, 0, 0, padding, ' ', 0)
+
+	fmt.Fprintln(writer, "ID\tNAME\tSTATE\tAPPLIED AT\tDURATION MS")
+
+	for _, entry := range report.Entries {
+		appliedAt, durationMs := "-", "-"
+
+		if entry.AppliedAt != nil {
+			appliedAt = entry.AppliedAt.UTC().Format(time.RFC3339)
+		}
+
+		if entry.DurationMs != nil {
+			durationMs = fmt.Sprint(*entry.DurationMs)
+		}
+
+		id := entry.ID.String()
+
+		if entry.Repeatable {
+			id = "-"
+		}
+
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n", id, entry.Name, entry.State, appliedAt, durationMs)
+	}
+
+	if err := writer.Flush(); err != nil {
+		log.Panic(err)
+	}
+
+	log.Printf(" Summary: Applied: %d, Pending: %d, Missing on disk: %d, Checksum changed: %d, Outdated: %d",
+		report.CountOf(migrator.StateApplied),
+		report.CountOf(migrator.StatePending),
+		report.CountOf(migrator.StateMissingOnDisk),
+		report.CountOf(migrator.StateChecksumChanged),
+		report.CountOf(migrator.StateOutdated),
+	)
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"path/filepath"
 
+	"github.com/servletcloud/Andmerada/internal/placeholder"
+	"github.com/servletcloud/Andmerada/internal/settings"
 	"github.com/servletcloud/Andmerada/internal/source"
 )
 
@@ -13,6 +15,9 @@ type Configuration struct {
 	NowID           source.ID
 	UpSQLTemplate   string
 	DownSQLTemplate string
+	Transaction     settings.TransactionMode
+	Isolation       settings.IsolationMode
+	Placeholders    placeholder.Values
 }
 
 type LintError struct {
@@ -43,8 +48,15 @@ func (linter *linter) lint(report *Report) error {
 	configurationLinter := &ConfigLinter{ProjectDir: linter.ProjectDir}
 	upSQLLinter := linter.newUpSQLLinter()
 	downSQLLinter := linter.newDownSQLLinter()
+	transactionLinter := &TransactionLinter{ProjectDir: linter.ProjectDir, MaxSQLFileSize: linter.MaxSQLFileSize}
+	sessionLinter := &SessionLinter{ProjectDir: linter.ProjectDir, MaxSQLFileSize: linter.MaxSQLFileSize}
+	placeholderLinter := &PlaceholderLinter{
+		ProjectDir:     linter.ProjectDir,
+		MaxSQLFileSize: linter.MaxSQLFileSize,
+		Values:         linter.Placeholders,
+	}
 
-	return source.TraverseAll(linter.ProjectDir, func(id source.ID, name string) { //nolint:wrapcheck
+	err := source.TraverseAll(linter.ProjectDir, func(id source.ID, name string) {
 		duplicatesLinter.LintSource(id, name)
 		futureLinter.LintSource(id, name)
 		countLinter.LintSource()
@@ -56,12 +68,52 @@ func (linter *linter) lint(report *Report) error {
 			return
 		}
 
+		transaction := settings.ResolveTransactionMode(linter.Transaction, configuration.Transaction)
+		isolation := settings.ResolveIsolationMode(linter.Isolation)
+
 		upSQLLinter.Lint(report, filepath.Join(name, configuration.Up.File))
+		transactionLinter.Lint(report, filepath.Join(name, configuration.Up.File), transaction)
+		sessionLinter.Lint(report, filepath.Join(name, configuration.Up.File), isolation)
+		placeholderLinter.Lint(report, filepath.Join(name, configuration.Up.File))
 
 		if !configuration.Down.Block {
 			downSQLLinter.Lint(report, filepath.Join(name, configuration.Down.File))
+			transactionLinter.Lint(report, filepath.Join(name, configuration.Down.File), transaction)
+			placeholderLinter.Lint(report, filepath.Join(name, configuration.Down.File))
 		}
 	})
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return linter.lintRepeatables(report, countLinter, transactionLinter, sessionLinter, placeholderLinter)
+}
+
+func (linter *linter) lintRepeatables(
+	report *Report,
+	countLinter *CountLinter,
+	transactionLinter *TransactionLinter,
+	sessionLinter *SessionLinter,
+	placeholderLinter *PlaceholderLinter,
+) error {
+	names, err := source.ScanRepeatables(linter.ProjectDir)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	sqlLinter := linter.newRepeatableSQLLinter()
+	transaction := settings.ResolveTransactionMode(linter.Transaction)
+	isolation := settings.ResolveIsolationMode(linter.Isolation)
+
+	for _, name := range names {
+		countLinter.LintSource()
+		sqlLinter.Lint(report, name)
+		transactionLinter.Lint(report, name, transaction)
+		sessionLinter.Lint(report, name, isolation)
+		placeholderLinter.Lint(report, name)
+	}
+
+	return nil
 }
 
 func (linter *linter) newUpSQLLinter() SQLLinter {
@@ -74,6 +126,16 @@ func (linter *linter) newUpSQLLinter() SQLLinter {
 	}
 }
 
+func (linter *linter) newRepeatableSQLLinter() SQLLinter {
+	return SQLLinter{
+		ProjectDir:          linter.ProjectDir,
+		MaxSQLFileSize:      linter.MaxSQLFileSize,
+		CreatedFromTemplate: nil,
+		ErrEmptyMsg:         "Repeatable migration file appears to be empty.",
+		ErrUntouchedMsg:     "",
+	}
+}
+
 func (linter *linter) newDownSQLLinter() SQLLinter {
 	errEmptyMsg := fmt.Sprint(
 		"The migration rollback file appears to be empty. ",
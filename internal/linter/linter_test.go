@@ -10,7 +10,9 @@ import (
 	"github.com/dustin/go-humanize"
 	"github.com/servletcloud/Andmerada/internal/linter"
 	"github.com/servletcloud/Andmerada/internal/osutil"
+	"github.com/servletcloud/Andmerada/internal/placeholder"
 	"github.com/servletcloud/Andmerada/internal/schema"
+	"github.com/servletcloud/Andmerada/internal/settings"
 	"github.com/servletcloud/Andmerada/internal/source"
 	"github.com/servletcloud/Andmerada/internal/ymlutil"
 	"github.com/stretchr/testify/assert"
@@ -111,6 +113,43 @@ func TestLint(t *testing.T) { //nolint:funlen
 		assert.Empty(t, report.Warnings)
 	})
 
+	t.Run("transaction: auto with the default BEGIN and COMMIT", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+
+		migrationDir := createTempMigration(t, dir, id2)
+
+		updateConfig(t, filepath.Join(migrationDir, "migration.yml"), func(conf *source.Configuration) {
+			conf.Transaction = settings.TransactionModeAuto
+		})
+
+		report := runLint(dir, nil)
+
+		assertHasError(t, report.Errors, "Transaction control is not allowed with `transaction: auto`")
+	})
+
+	t.Run("transaction: none overrides the project default", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+
+		migrationDir := createTempMigration(t, dir, id2)
+
+		updateConfig(t, filepath.Join(migrationDir, "migration.yml"), func(conf *source.Configuration) {
+			conf.Transaction = settings.TransactionModeNone
+		})
+
+		lintConfig := &linter.Configuration{ //nolint:exhaustruct
+			MaxSQLFileSize: 1 * humanize.KiByte,
+			NowID:          source.NewIDFromNow(),
+			Transaction:    settings.TransactionModeAuto,
+		}
+		report := runLint(dir, lintConfig)
+
+		assert.Empty(t, report.Errors)
+	})
+
 	t.Run("duplicate migration ID", func(t *testing.T) {
 		t.Parallel()
 
@@ -144,6 +183,52 @@ func TestLint(t *testing.T) { //nolint:funlen
 		assertHasError(t, report.Errors, "File is too big:")
 	})
 
+	t.Run("repeatable migrations are linted", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		repeatableDir := filepath.Join(dir, source.RepeatableDirName)
+
+		require.NoError(t, os.Mkdir(repeatableDir, osutil.DirPerm0755))
+		require.NoError(t, os.WriteFile(filepath.Join(repeatableDir, "empty.sql"), nil, osutil.FilePerm0644))
+		require.NoError(t, os.WriteFile(
+			filepath.Join(repeatableDir, "users_view.sql"),
+			[]byte("BEGIN;\nCREATE OR REPLACE VIEW users_view AS SELECT 1;\nCOMMIT;"),
+			osutil.FilePerm0644,
+		))
+
+		lintConfig := &linter.Configuration{ //nolint:exhaustruct
+			MaxSQLFileSize: 1 * humanize.KiByte,
+			NowID:          source.NewIDFromNow(),
+			Transaction:    settings.TransactionModeAuto,
+		}
+		report := runLint(dir, lintConfig)
+
+		assertHasError(t, report.Warnings, "Repeatable migration file appears to be empty.")
+		assertHasError(t, report.Errors, "Transaction control is not allowed with `transaction: auto`")
+		assert.Len(t, report.Warnings, 1, "repeatable migrations count as migrations")
+	})
+
+	t.Run("unresolved placeholders", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+
+		migrationDir := createTempMigration(t, dir, id2)
+		upSQL := "GRANT SELECT ON users TO ${app_owner};\nINSERT INTO audit VALUES (${migration_id}, ${ticket});"
+		require.NoError(t, os.WriteFile(filepath.Join(migrationDir, "up.sql"), []byte(upSQL), osutil.FilePerm0644))
+
+		lintConfig := &linter.Configuration{ //nolint:exhaustruct
+			MaxSQLFileSize: 1 * humanize.KiByte,
+			NowID:          source.NewIDFromNow(),
+			Placeholders:   placeholder.Values{"app_owner": "app_owner_stg"},
+		}
+		report := runLint(dir, lintConfig)
+
+		require.Len(t, report.Errors, 1)
+		assertHasError(t, report.Errors, "Unresolved placeholders: ${ticket}.")
+	})
+
 	t.Run("warning if there are migrations in the future", func(t *testing.T) {
 		t.Parallel()
 
@@ -189,6 +274,9 @@ func runLint(dir string, configOverride *linter.Configuration) linter.Report {
 	if configOverride != nil {
 		config.MaxSQLFileSize = configOverride.MaxSQLFileSize
 		config.NowID = configOverride.NowID
+		config.Transaction = configOverride.Transaction
+		config.Isolation = configOverride.Isolation
+		config.Placeholders = configOverride.Placeholders
 	}
 
 	report := linter.Report{} //nolint:exhaustruct
@@ -0,0 +1,50 @@
+package linter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/servletcloud/Andmerada/internal/placeholder"
+)
+
+// PlaceholderLinter reports ${name} placeholders that have no value, which fail 'andmerada migrate'.
+type PlaceholderLinter struct {
+	ProjectDir     string
+	MaxSQLFileSize int64
+	Values         placeholder.Values
+}
+
+func (linter *PlaceholderLinter) Lint(report *Report, relative string) {
+	path := filepath.Join(linter.ProjectDir, relative)
+
+	// Missing, unreadable and too big files are reported by SQLLinter.
+	stat, err := os.Stat(path)
+	if err != nil || stat.IsDir() || stat.Size() > linter.MaxSQLFileSize {
+		return
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	unresolved := placeholder.Unresolved(string(content), linter.Values)
+
+	if len(unresolved) == 0 {
+		return
+	}
+
+	quoted := make([]string, 0, len(unresolved))
+
+	for _, name := range unresolved {
+		quoted = append(quoted, "${"+name+"}")
+	}
+
+	title := fmt.Sprintf(
+		"Unresolved placeholders: %s. Define them in andmerada.yml, as %s<NAME> environment variables or with --set",
+		strings.Join(quoted, ", "), placeholder.EnvPrefix,
+	)
+	report.AddError(title, relative)
+}
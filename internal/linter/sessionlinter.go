@@ -0,0 +1,46 @@
+package linter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/servletcloud/Andmerada/internal/settings"
+	"github.com/servletcloud/Andmerada/internal/sqlparse"
+)
+
+// SessionLinter reports SET statements without LOCAL when `isolation: none` lets their effect
+// leak from one migration into the next on the shared connection.
+type SessionLinter struct {
+	ProjectDir     string
+	MaxSQLFileSize int64
+}
+
+func (linter *SessionLinter) Lint(report *Report, relative string, isolation settings.IsolationMode) {
+	if isolation != settings.IsolationNone {
+		return
+	}
+
+	path := filepath.Join(linter.ProjectDir, relative)
+
+	// Missing, unreadable and too big files are reported by SQLLinter.
+	stat, err := os.Stat(path)
+	if err != nil || stat.IsDir() || stat.Size() > linter.MaxSQLFileSize {
+		return
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	script := sqlparse.Parse(string(content))
+
+	if statement, found := script.FirstSessionSet(); found {
+		title := fmt.Sprintf(
+			"The setting outlives the migration with `isolation: none`, use SET LOCAL or another isolation (line %d): %s",
+			statement.Line, statement.Text,
+		)
+		report.AddWarning(title, relative)
+	}
+}
@@ -0,0 +1,62 @@
+package linter_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/servletcloud/Andmerada/internal/linter"
+	"github.com/servletcloud/Andmerada/internal/osutil"
+	"github.com/servletcloud/Andmerada/internal/settings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionLinter(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	writeSQL := func(t *testing.T, name string, content string) {
+		t.Helper()
+
+		require.NoError(t, osutil.WriteFileExcl(filepath.Join(dir, name), content))
+	}
+
+	writeSQL(t, "local.sql", "SET LOCAL lock_timeout = '5s';\nCREATE TABLE users (id INT);")
+	writeSQL(t, "session.sql", "CREATE TABLE users (id INT);\nSET search_path TO app;")
+
+	lint := func(relative string, isolation settings.IsolationMode) linter.Report {
+		report := linter.Report{} //nolint:exhaustruct
+		linter := &linter.SessionLinter{ProjectDir: dir, MaxSQLFileSize: 1024}
+
+		linter.Lint(&report, relative, isolation)
+
+		return report
+	}
+
+	t.Run("SET LOCAL is valid without isolation", func(t *testing.T) {
+		t.Parallel()
+
+		report := lint("local.sql", settings.IsolationNone)
+
+		assert.Empty(t, report.Errors)
+		assert.Empty(t, report.Warnings)
+	})
+
+	t.Run("SET without LOCAL is a warning without isolation", func(t *testing.T) {
+		t.Parallel()
+
+		report := lint("session.sql", settings.IsolationNone)
+
+		assert.Empty(t, report.Errors)
+		assertHasError(t, report.Warnings, "outlives the migration with `isolation: none`")
+		assertHasError(t, report.Warnings, "(line 2): SET search_path TO app;")
+	})
+
+	t.Run("Nothing is checked with isolation", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Empty(t, lint("session.sql", settings.IsolationReset).Warnings)
+		assert.Empty(t, lint("session.sql", settings.IsolationReconnect).Warnings)
+	})
+}
@@ -60,7 +60,8 @@ func (linter *SQLLinter) lintFileSize(report *Report, relative string, size int6
 func (linter *SQLLinter) lintUntouched(report *Report, relative string, size int64) {
 	templateSize := int64(len(linter.CreatedFromTemplate))
 
-	if size != templateSize {
+	// Files that are not created from a template, such as repeatable migrations, cannot be untouched.
+	if templateSize == 0 || size != templateSize {
 		return
 	}
 
@@ -0,0 +1,54 @@
+package linter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/servletcloud/Andmerada/internal/settings"
+	"github.com/servletcloud/Andmerada/internal/sqlparse"
+)
+
+// TransactionLinter reports statements that conflict with `transaction: auto`,
+// in which Andmerada wraps the SQL file in BEGIN ... COMMIT itself.
+type TransactionLinter struct {
+	ProjectDir     string
+	MaxSQLFileSize int64
+}
+
+func (linter *TransactionLinter) Lint(report *Report, relative string, mode settings.TransactionMode) {
+	if mode != settings.TransactionModeAuto {
+		return
+	}
+
+	path := filepath.Join(linter.ProjectDir, relative)
+
+	// Missing, unreadable and too big files are reported by SQLLinter.
+	stat, err := os.Stat(path)
+	if err != nil || stat.IsDir() || stat.Size() > linter.MaxSQLFileSize {
+		return
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	script := sqlparse.Parse(string(content))
+
+	if statement, found := script.FirstTransactionControl(); found {
+		title := fmt.Sprintf(
+			"Transaction control is not allowed with `transaction: auto` (line %d): %s",
+			statement.Line, statement.Text,
+		)
+		report.AddError(title, relative)
+	}
+
+	if statement, found := script.FirstNonTransactional(); found {
+		title := fmt.Sprintf(
+			"The statement cannot run inside the transaction of `transaction: auto` (line %d): %s",
+			statement.Line, statement.Text,
+		)
+		report.AddError(title, relative)
+	}
+}
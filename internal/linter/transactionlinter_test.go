@@ -0,0 +1,77 @@
+package linter_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/servletcloud/Andmerada/internal/linter"
+	"github.com/servletcloud/Andmerada/internal/osutil"
+	"github.com/servletcloud/Andmerada/internal/settings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionLinter(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	writeSQL := func(t *testing.T, name string, content string) {
+		t.Helper()
+
+		require.NoError(t, osutil.WriteFileExcl(filepath.Join(dir, name), content))
+	}
+
+	writeSQL(t, "plain.sql", "CREATE TABLE users (id INT);\nCREATE TABLE roles (id INT);")
+	writeSQL(t, "explicit.sql", "BEGIN;\nCREATE TABLE users (id INT);\nCOMMIT;")
+	writeSQL(t, "concurrently.sql", "-- comment\nCREATE INDEX CONCURRENTLY idx_users ON users (id);")
+
+	lint := func(relative string, mode settings.TransactionMode) linter.Report {
+		report := linter.Report{} //nolint:exhaustruct
+		linter := &linter.TransactionLinter{ProjectDir: dir, MaxSQLFileSize: 1024}
+
+		linter.Lint(&report, relative, mode)
+
+		return report
+	}
+
+	t.Run("Plain SQL is valid in the auto mode", func(t *testing.T) {
+		t.Parallel()
+
+		report := lint("plain.sql", settings.TransactionModeAuto)
+
+		assert.Empty(t, report.Errors)
+		assert.Empty(t, report.Warnings)
+	})
+
+	t.Run("Transaction control is an error in the auto mode", func(t *testing.T) {
+		t.Parallel()
+
+		report := lint("explicit.sql", settings.TransactionModeAuto)
+
+		assertHasError(t, report.Errors, "Transaction control is not allowed with `transaction: auto` (line 1): BEGIN;")
+	})
+
+	t.Run("Non-transactional statements are an error in the auto mode", func(t *testing.T) {
+		t.Parallel()
+
+		report := lint("concurrently.sql", settings.TransactionModeAuto)
+
+		assertHasError(t, report.Errors, "cannot run inside the transaction of `transaction: auto` (line 2)")
+	})
+
+	t.Run("Nothing is checked in the none mode", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Empty(t, lint("explicit.sql", settings.TransactionModeNone).Errors)
+		assert.Empty(t, lint("concurrently.sql", settings.TransactionModeNone).Errors)
+	})
+
+	t.Run("Missing files are left to SQLLinter", func(t *testing.T) {
+		t.Parallel()
+
+		report := lint("missing.sql", settings.TransactionModeAuto)
+
+		assert.Empty(t, report.Errors)
+	})
+}
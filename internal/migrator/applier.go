@@ -3,6 +3,7 @@ package migrator
 import (
 	"cmp"
 	"context"
+	"errors"
 	"iter"
 	"log"
 	"maps"
@@ -10,23 +11,28 @@ import (
 	"slices"
 	"time"
 
-	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
+	"github.com/servletcloud/Andmerada/internal/placeholder"
 	"github.com/servletcloud/Andmerada/internal/project"
+	"github.com/servletcloud/Andmerada/internal/settings"
 	"github.com/servletcloud/Andmerada/internal/source"
 )
 
-type Report struct {
-	PendingCount int
-}
-
 type ApplyOptions struct {
 	MaxSQLFileSize    int64
 	DatabaseURL       string
 	Project           project.Project
 	Limit             int
+	ToID              source.ID
 	DryRun            bool
+	Rehearse          bool
 	SkipPreValidation bool
+	AllowDrift        bool
+	LockTimeout       time.Duration
+	OutOfOrder        settings.OutOfOrderPolicy
+	Filter            string
+	// Placeholders override the placeholders of andmerada.yml, e.g. the ones from environment variables and --set.
+	Placeholders placeholder.Values
 }
 
 type applier struct {
@@ -35,18 +41,43 @@ type applier struct {
 	projectDir        string
 	migrationsTable   string
 	limit             int
+	toID              source.ID
 	dryRun            bool
+	rehearse          bool
 	skipPreValidation bool
+	allowDrift        bool
+	lockTimeout       time.Duration
+	transaction       settings.TransactionMode
+	execution         settings.ExecutionMode
+	retry             settings.Retry
+	session           settings.Session
+	isolation         settings.IsolationMode
+	failOnWarning     bool
+	outOfOrder        settings.OutOfOrderPolicy
+	filterExpression  string
+	searchPath        []string
+	selfUpgrade       settings.SelfUpgradeMode
+	callbackPaths     project.Callbacks
+	placeholders      placeholder.Values
 
 	report         *Report
 	migrationsRepo *Migrations
+	history        *historyRecorder
+	lock           *Lock
 	loader         source.Loader
+	filter         *source.IDFilter
+	callbacks      map[project.Callback]string
 	connection     *pgx.Conn
+	lockConnection *pgx.Conn
+	noticeTarget   noticeTarget
 }
 
+// sourceRef refers to a pending migration. The name of a versioned migration is its directory,
+// the name of a repeatable one is its file, see source.ScanRepeatables.
 type sourceRef struct {
-	id   source.ID
-	name string
+	id         source.ID
+	name       string
+	repeatable bool
 }
 
 const (
@@ -58,19 +89,45 @@ func ApplyPending(ctx context.Context, options ApplyOptions, report *Report) err
 	migrationsTable := projectConfiguration.MigrationsTableName
 
 	report.PendingCount = 0
+	report.LatestAppliedID = source.EmptyMigrationID
+	report.OutOfOrderIDs = nil
+	report.SkippedIDs = nil
+	report.Entries = nil
 
 	applier := &applier{
 		maxSQLFileSize:    options.MaxSQLFileSize,
 		databaseURL:       options.DatabaseURL,
 		projectDir:        options.Project.Dir,
 		limit:             options.Limit,
+		toID:              options.ToID,
 		dryRun:            options.DryRun,
+		rehearse:          options.Rehearse,
 		skipPreValidation: options.SkipPreValidation,
+		allowDrift:        options.AllowDrift,
+		lockTimeout:       options.LockTimeout,
+		transaction:       projectConfiguration.Transaction,
+		execution:         projectConfiguration.Execution,
+		retry:             projectConfiguration.Retry,
+		session:           projectConfiguration.Session,
+		isolation:         settings.ResolveIsolationMode(projectConfiguration.Isolation),
+		failOnWarning:     projectConfiguration.FailOnWarning,
+		outOfOrder:        settings.ResolveOutOfOrderPolicy(projectConfiguration.OutOfOrder, options.OutOfOrder),
+		filterExpression:  options.Filter,
+		searchPath:        projectConfiguration.SearchPath,
+		selfUpgrade:       projectConfiguration.SelfUpgrade,
+		callbackPaths:     options.Project.Callbacks,
+		placeholders:      placeholder.Merge(projectConfiguration.Placeholders, options.Placeholders),
 		report:            report,
 		migrationsTable:   migrationsTable,
-		migrationsRepo:    &Migrations{TableName: migrationsTable},
+		migrationsRepo:    &Migrations{TableName: migrationsTable, CreateSchema: projectConfiguration.CreateSchema},
+		history:           newHistoryRecorder(migrationsTable, options.Project.Dir),
+		lock:              &Lock{TableName: migrationsTable},
 		loader:            source.Loader{MaxSQLFileSize: options.MaxSQLFileSize},
+		filter:            nil,
+		callbacks:         make(map[project.Callback]string),
 		connection:        nil,
+		lockConnection:    nil,
+		noticeTarget:      noticeTarget{name: "", entry: nil},
 	}
 
 	defer applier.close(ctx)
@@ -79,12 +136,29 @@ func ApplyPending(ctx context.Context, options ApplyOptions, report *Report) err
 }
 
 func (applier *applier) applyPending(ctx context.Context) error {
+	if err := applier.compileFilter(); err != nil {
+		return wrapError(err, ErrTypeFilterMigrations)
+	}
+
+	if err := applier.loadCallbacks(); err != nil {
+		return wrapError(err, ErrTypeCallback)
+	}
+
 	sourceIDToName, err := source.ScanAll(applier.projectDir)
 	if err != nil {
 		return wrapError(err, ErrTypeListMigrationsOnDisk)
 	}
 
-	if len(sourceIDToName) == 0 {
+	repeatableNames, err := source.ScanRepeatables(applier.projectDir)
+	if err != nil {
+		return wrapError(err, ErrTypeListMigrationsOnDisk)
+	}
+
+	if err := applier.ensureTargetExists(sourceIDToName); err != nil {
+		return wrapError(err, ErrTypeMigrateTarget)
+	}
+
+	if len(sourceIDToName) == 0 && len(repeatableNames) == 0 {
 		return nil
 	}
 
@@ -92,39 +166,115 @@ func (applier *applier) applyPending(ctx context.Context) error {
 		return wrapError(err, ErrTypeDBConnect)
 	}
 
-	appliedIDs, err := applier.scanAppliedMigrations(ctx, maps.Keys(sourceIDToName))
-	if err != nil {
-		needsToRunDDL := isPgErrorOfCode(err, pgerrcode.UndefinedTable)
+	if err := applier.acquireLock(ctx); err != nil {
+		return wrapError(err, ErrTypeAcquireLock)
+	}
 
-		if !needsToRunDDL {
-			return wrapError(err, ErrTypeScanAppliedMigrations)
-		}
+	defer applier.releaseLock(ctx)
 
-		if err := applier.runDDL(ctx); err != nil {
-			return wrapError(err, ErrTypeCreateDDL)
-		}
+	if err := applier.prepareSchema(ctx); err != nil {
+		return err
+	}
+
+	appliedIDs, err := applier.scanAppliedMigrations(ctx, maps.Keys(sourceIDToName))
+	if err != nil && !isUndefinedTableError(err) {
+		return wrapError(err, ErrTypeScanAppliedMigrations)
+	}
+
+	if err := applier.verifyChecksums(ctx, sourceIDToName); err != nil {
+		return wrapError(err, ErrTypeChecksumDrift)
 	}
 
 	for _, appliedID := range appliedIDs {
 		delete(sourceIDToName, appliedID)
 	}
 
+	if err := applier.filterSources(sourceIDToName); err != nil {
+		return wrapError(err, ErrTypeFilterMigrations)
+	}
+
 	sourceRefs := applier.toSortedSourceRefs(sourceIDToName)
+	versionedLeftBehind := len(sourceRefs) < len(sourceIDToName) || len(applier.report.SkippedIDs) > 0
+
+	repeatableRefs, err := applier.pendingRepeatables(ctx, repeatableNames, versionedLeftBehind)
+	if err != nil {
+		return err
+	}
+
+	sourceRefs = append(sourceRefs, repeatableRefs...)
 	applier.report.PendingCount = len(sourceRefs)
+	applier.report.addPendingEntries(sourceRefs)
+
+	if err := applier.checkOutOfOrder(appliedIDs, sourceRefs); err != nil {
+		return wrapError(err, ErrTypeOutOfOrder)
+	}
 
 	if err := applier.preValidateSources(sourceRefs); err != nil {
 		return wrapError(err, ErrTypePreValidateSources)
 	}
 
+	if applier.rehearse {
+		return applier.rehearseAll(ctx, sourceRefs)
+	}
+
 	return applier.applyAll(ctx, sourceRefs)
 }
 
-func (applier *applier) runDDL(ctx context.Context) error {
+// acquireLock takes the migrations lock on a dedicated connection that lives for the whole run,
+// so that reconnecting the connection of the migrations, e.g. for isolation or a retry, never releases it.
+func (applier *applier) acquireLock(ctx context.Context) error {
 	if applier.dryRun {
 		return nil
 	}
 
-	return applier.migrationsRepo.RunDDL(ctx, applier.connection)
+	connection, err := connect(ctx, applier.databaseURL, applier.searchPath)
+
+	applier.lockConnection = connection
+
+	if err != nil {
+		return err
+	}
+
+	if err := setApplicationName(ctx, connection, "migrate"); err != nil {
+		return err
+	}
+
+	return applier.lock.Acquire(ctx, connection, applier.lockTimeout)
+}
+
+func (applier *applier) releaseLock(ctx context.Context) {
+	if applier.dryRun || applier.lockConnection == nil {
+		return
+	}
+
+	if err := applier.lock.Release(ctx, applier.lockConnection); err != nil {
+		log.Println("Failed to release the migrations lock:", err)
+	}
+}
+
+// checkLock fails when the lock connection is lost, because the lock is released with it.
+func (applier *applier) checkLock(ctx context.Context) error {
+	if applier.dryRun {
+		return nil
+	}
+
+	if err := applier.lockConnection.Ping(ctx); err != nil {
+		return &LockLostError{Cause: err}
+	}
+
+	return nil
+}
+
+// prepareSchema creates or upgrades the bookkeeping schema. Dry runs and rehearsals leave it as is,
+// but still refuse a schema that is newer than this binary maintains.
+func (applier *applier) prepareSchema(ctx context.Context) error {
+	if applier.dryRun || applier.rehearse {
+		_, err := checkSchemaVersion(ctx, applier.connection, applier.migrationsRepo)
+
+		return err
+	}
+
+	return prepareSchema(ctx, applier.connection, applier.migrationsRepo, applier.selfUpgrade)
 }
 
 func (applier *applier) scanAppliedMigrations(
@@ -141,17 +291,89 @@ func (applier *applier) scanAppliedMigrations(
 	return applier.migrationsRepo.ScanApplied(ctx, applier.connection, idMin, idMax)
 }
 
+func (applier *applier) verifyChecksums(ctx context.Context, sourceIDToName map[source.ID]string) error {
+	applied, err := applier.migrationsRepo.ListApplied(ctx, applier.connection)
+	if err != nil && !isUndefinedTableError(err) {
+		return wrapError(err, ErrTypeScanAppliedMigrations)
+	}
+
+	checker := driftChecker{projectDir: applier.projectDir, loader: applier.loader}
+	drifts := checker.detect(applied, sourceIDToName)
+
+	if applier.allowDrift {
+		logDrifts(drifts)
+	}
+
+	return checkDrifts(drifts, applier.allowDrift)
+}
+
+func (applier *applier) ensureTargetExists(sourceIDToName map[source.ID]string) error {
+	if applier.toID == source.EmptyMigrationID {
+		return nil
+	}
+
+	if _, found := sourceIDToName[applier.toID]; !found {
+		return &SourceNotFoundError{ID: applier.toID}
+	}
+
+	return nil
+}
+
+func (applier *applier) compileFilter() error {
+	if applier.filterExpression == "" {
+		return nil
+	}
+
+	filter, err := source.NewIDFilter(applier.filterExpression, time.Now())
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	applier.filter = &filter
+
+	return nil
+}
+
+// filterSources removes pending migrations that do not match the filter and reports them as skipped.
+func (applier *applier) filterSources(sourceIDToName map[source.ID]string) error {
+	if applier.filter == nil {
+		return nil
+	}
+
+	for id := range sourceIDToName {
+		matches, err := applier.filter.Test(id)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		if !matches {
+			delete(sourceIDToName, id)
+			applier.report.SkippedIDs = append(applier.report.SkippedIDs, id)
+		}
+	}
+
+	slices.Sort(applier.report.SkippedIDs)
+
+	return nil
+}
+
 func (applier *applier) toSortedSourceRefs(sources map[source.ID]string) []sourceRef {
 	result := make([]sourceRef, 0, len(sources))
 
 	for id, name := range sources {
-		result = append(result, sourceRef{id, name})
+		result = append(result, sourceRef{id: id, name: name, repeatable: false})
 	}
 
 	slices.SortFunc(result, func(a, b sourceRef) int {
 		return cmp.Compare(a.id, b.id)
 	})
 
+	if applier.toID != source.EmptyMigrationID {
+		result = slices.DeleteFunc(result, func(ref sourceRef) bool {
+			return ref.id > applier.toID
+		})
+	}
+
 	if applier.limit == NoLimit {
 		return result
 	}
@@ -161,6 +383,86 @@ func (applier *applier) toSortedSourceRefs(sources map[source.ID]string) []sourc
 	return result[:upperBound]
 }
 
+// pendingRepeatables returns the repeatable migrations whose SQL differs from the one they were
+// last applied with. They are not applied while versioned migrations are left pending by --limit,
+// --to or --filter, because repeatable migrations usually depend on the latest schema.
+func (applier *applier) pendingRepeatables(
+	ctx context.Context,
+	names []string,
+	versionedLeftBehind bool,
+) ([]sourceRef, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	if versionedLeftBehind {
+		log.Println("Repeatable migrations are not applied because some pending migrations are left behind.")
+
+		return nil, nil
+	}
+
+	applied, err := applier.migrationsRepo.ListRepeatables(ctx, applier.connection)
+	if err != nil && !isUndefinedTableError(err) {
+		return nil, wrapError(err, ErrTypeScanAppliedMigrations)
+	}
+
+	idToChecksum := make(map[source.ID]string, len(applied))
+	for _, migration := range applied {
+		idToChecksum[migration.ID] = migration.SQLUpSHA256
+	}
+
+	var result []sourceRef
+
+	src := source.Source{} //nolint:exhaustruct
+
+	for _, name := range names {
+		ref := sourceRef{id: source.RepeatableID(name), name: name, repeatable: true}
+
+		if err := applier.loadSource(ref, &src); err != nil {
+			return nil, wrapError(err, ErrTypeLoadMigration)
+		}
+
+		if idToChecksum[ref.id] != Sha256ToHexStr(src.UpSQL) {
+			result = append(result, ref)
+		}
+	}
+
+	return result, nil
+}
+
+func (applier *applier) checkOutOfOrder(appliedIDs []source.ID, sourceRefs []sourceRef) error {
+	if len(appliedIDs) == 0 {
+		return nil
+	}
+
+	latestAppliedID := slices.Max(appliedIDs)
+	applier.report.LatestAppliedID = latestAppliedID
+
+	for _, ref := range sourceRefs {
+		if ref.id < latestAppliedID {
+			applier.report.OutOfOrderIDs = append(applier.report.OutOfOrderIDs, ref.id)
+		}
+	}
+
+	outOfOrderIDs := applier.report.OutOfOrderIDs
+
+	if len(outOfOrderIDs) == 0 {
+		return nil
+	}
+
+	err := &OutOfOrderError{IDs: outOfOrderIDs, LatestAppliedID: latestAppliedID}
+
+	switch applier.outOfOrder {
+	case settings.OutOfOrderFail:
+		return err
+	case settings.OutOfOrderWarn:
+		log.Printf("Warning: %v", err)
+	case settings.OutOfOrderAllow:
+	}
+
+	return nil
+}
+
 func (applier *applier) preValidateSources(sourceRefs []sourceRef) error {
 	if applier.skipPreValidation {
 		return nil
@@ -168,52 +470,195 @@ func (applier *applier) preValidateSources(sourceRefs []sourceRef) error {
 
 	source := source.Source{} //nolint:exhaustruct
 
-	for _, ref := range sourceRefs {
-		if err := applier.loader.ValidateSource(filepath.Join(applier.projectDir, ref.name), &source); err != nil {
-			return &LoadSourceError{Cause: err, Name: ref.name}
+	for i, ref := range sourceRefs {
+		if err := applier.preValidateSource(ref, &source); err != nil {
+			applier.report.Entries[i].fail(err)
+
+			return err
 		}
 	}
 
 	return nil
 }
 
+// preValidateSource loads the migration and prepares its SQL for execution, which resolves
+// its placeholders and checks it against the transaction mode.
+func (applier *applier) preValidateSource(ref sourceRef, source *source.Source) error {
+	if err := applier.loadSource(ref, source); err != nil {
+		return err
+	}
+
+	_, err := applier.upSQL(ref, source)
+
+	return err
+}
+
 func (applier *applier) applyAll(ctx context.Context, sourceRefs []sourceRef) error {
-	source := source.Source{} //nolint:exhaustruct
+	if len(sourceRefs) == 0 {
+		if applier.hasCallback(project.CallbackBeforeMigrate) || applier.hasCallback(project.CallbackAfterMigrate) {
+			log.Println("Callbacks before_migrate and after_migrate are skipped, because no migrations are pending.")
+		}
 
-	for _, ref := range sourceRefs {
-		name := ref.name
+		return nil
+	}
 
-		if err := applier.loadSource(ref, &source); err != nil {
-			return wrapError(err, ErrTypeLoadMigration)
+	if err := applier.runCallback(ctx, project.CallbackBeforeMigrate); err != nil {
+		return wrapError(err, ErrTypeCallback)
+	}
+
+	source := source.Source{} //nolint:exhaustruct
+
+	for i, ref := range sourceRefs {
+		if err := applier.applySource(ctx, ref, &source, &applier.report.Entries[i]); err != nil {
+			return err
 		}
+	}
+
+	if err := applier.runCallback(ctx, project.CallbackAfterMigrate); err != nil {
+		return wrapError(err, ErrTypeCallback)
+	}
 
-		if duration, err := applier.applyMigration(ctx, source.UpSQL, ref); err != nil {
-			return wrapError(&ApplyMigrationError{Cause: err, Name: name}, ErrTypeApplyMigration)
-		} else if err := applier.registerMigration(ctx, ref, &source, duration); err != nil {
-			return wrapError(err, ErrTypeRegisterMigration)
+	return nil
+}
+
+func (applier *applier) applySource(
+	ctx context.Context,
+	ref sourceRef,
+	source *source.Source,
+	entry *ReportEntry,
+) error {
+	if err := applier.loadSource(ref, source); err != nil {
+		entry.fail(err)
+
+		return wrapError(err, ErrTypeLoadMigration)
+	}
+
+	sql, err := applier.upSQL(ref, source)
+	if err != nil {
+		entry.fail(err)
+
+		return wrapError(err, ErrTypeLoadMigration)
+	}
+
+	if err := applier.checkLock(ctx); err != nil {
+		return wrapError(err, ErrTypeLockLost)
+	}
+
+	if err := applier.runMigrationCallback(ctx, project.CallbackBeforeEach, ref, source); err != nil {
+		entry.fail(err)
+		applier.runAfterErrorCallback(ctx, ref, source)
+
+		return wrapError(err, ErrTypeCallback)
+	}
+
+	// In the auto transaction mode the warnings are checked before COMMIT, so fail_on_warning rolls the migration back.
+	checkWarnings := func(context.Context) error {
+		return applier.checkWarnings(ref, entry, true)
+	}
+
+	stopNotices := applier.collectNotices(ref, entry)
+	duration, err := applier.applyMigrationWithRetry(ctx, sql, source, ref, checkWarnings)
+	entry.Duration = duration
+
+	stopNotices()
+
+	if err != nil {
+		entry.fail(err)
+		applier.runAfterErrorCallback(ctx, ref, source)
+
+		if warningErr := new(WarningError); errors.As(err, &warningErr) {
+			return wrapError(err, ErrTypeWarning)
 		}
+
+		return wrapError(&ApplyMigrationError{Cause: err, Name: ref.name}, ErrTypeApplyMigration)
+	}
+
+	// The session is isolated before the registration, so that e.g. a plain SET search_path
+	// of the migration cannot redirect the bookkeeping to another schema.
+	if err := applier.isolateSession(ctx); err != nil {
+		entry.fail(err)
+
+		return wrapError(&IsolateSessionError{Cause: err, Name: ref.name}, ErrTypeIsolateSession)
+	}
+
+	if err := applier.registerMigration(ctx, ref, source, duration); err != nil {
+		entry.fail(err)
+
+		return wrapError(err, ErrTypeRegisterMigration)
+	}
+
+	if !applier.dryRun {
+		entry.Status = EntryApplied
+	}
+
+	// The migration is applied and registered at this point, so a failed after_each leaves its entry as is.
+	if err := applier.runMigrationCallback(ctx, project.CallbackAfterEach, ref, source); err != nil {
+		return wrapError(err, ErrTypeCallback)
+	}
+
+	// Only a migration with `transaction: none` gets here with warnings. It is committed and stays applied.
+	if err := applier.checkWarnings(ref, entry, false); err != nil {
+		return wrapError(err, ErrTypeWarning)
 	}
 
 	return nil
 }
 
 func (applier *applier) loadSource(ref sourceRef, out *source.Source) error {
-	dir := filepath.Join(applier.projectDir, ref.name)
+	var err error
 
-	if err := applier.loader.LoadSource(dir, out); err != nil {
+	if ref.repeatable {
+		err = applier.loader.LoadRepeatable(applier.projectDir, ref.name, out)
+	} else {
+		err = applier.loader.LoadSource(filepath.Join(applier.projectDir, ref.name), out)
+	}
+
+	if err != nil {
 		return &LoadSourceError{Cause: err, Name: ref.name}
 	}
 
 	return nil
 }
 
-func (applier *applier) applyMigration(ctx context.Context, sql string, ref sourceRef) (time.Duration, error) {
+func (applier *applier) transactionMode(source *source.Source) settings.TransactionMode {
+	return settings.ResolveTransactionMode(applier.transaction, source.Configuration.Transaction)
+}
+
+func (applier *applier) executionMode(source *source.Source) settings.ExecutionMode {
+	return settings.ResolveExecutionMode(applier.execution, source.Configuration.Execution)
+}
+
+// upSQL returns the SQL that applies the migration. Placeholders are resolved here rather than on load,
+// so checksums are computed on the files as they are on disk.
+func (applier *applier) upSQL(ref sourceRef, source *source.Source) (string, error) {
+	values := migrationPlaceholders(applier.placeholders, ref.id, source.Configuration.Name, applier.migrationsTable)
+
+	sql, err := placeholder.Replace(source.UpSQL, values)
+	if err != nil {
+		return "", &LoadSourceError{Cause: err, Name: ref.name}
+	}
+
+	sql, err = prepareSQL(applier.transactionMode(source), sql)
+	if err != nil {
+		return "", &LoadSourceError{Cause: err, Name: ref.name}
+	}
+
+	return sql, nil
+}
+
+func (applier *applier) applyMigration(
+	ctx context.Context,
+	sql string,
+	source *source.Source,
+	ref sourceRef,
+	beforeCommit func(ctx context.Context) error,
+) (time.Duration, error) {
 	startTime := time.Now()
 
 	log.Printf("Applying %q, please wait...", ref.name)
 
-	if err := applier.executeMigrationSQL(ctx, sql); err != nil {
-		return 0, err
+	if err := applier.executeMigrationSQL(ctx, sql, source, ref, beforeCommit); err != nil {
+		return time.Since(startTime), err
 	}
 
 	duration := time.Since(startTime)
@@ -224,26 +669,56 @@ func (applier *applier) applyMigration(ctx context.Context, sql string, ref sour
 	return duration, nil
 }
 
-func (applier *applier) executeMigrationSQL(ctx context.Context, sql string) error {
-	pgConn := applier.connection.PgConn()
+func (applier *applier) recordAttempt(
+	ctx context.Context,
+	ref sourceRef,
+	source *source.Source,
+	startedAt time.Time,
+	err error,
+) {
+	if applier.dryRun {
+		return
+	}
+
+	applier.history.record(ctx, applier.connection, OperationApply, ref.id, source.Configuration.Name, startedAt, err)
+}
 
-	if isConnectionInTransaction(pgConn) {
-		panic("the connection is not allowed to be in an active transaction")
+// executeMigrationSQL runs the SQL with the session settings of the migration, which are restored afterwards.
+// beforeCommit is passed to execMigration.
+func (applier *applier) executeMigrationSQL(
+	ctx context.Context,
+	sql string,
+	source *source.Source,
+	ref sourceRef,
+	beforeCommit func(ctx context.Context) error,
+) error {
+	if applier.dryRun {
+		return nil
 	}
 
-	if !applier.dryRun {
-		if err := execSimple(ctx, pgConn, sql); err != nil {
-			return &ExecSQLError{Cause: err, SQL: sql}
+	session := settings.ResolveSession(applier.session, source.Configuration.Session)
+	parameters := sessionParameters(session, applicationNamePrefix+ref.name)
+
+	previous, err := applySession(ctx, applier.connection, parameters)
+
+	defer func() {
+		if err := restoreSession(ctx, applier.connection, previous); err != nil {
+			log.Println("Warning: cannot restore the session settings after the migration:", err)
 		}
+	}()
+
+	if err != nil {
+		return err
 	}
 
-	if isConnectionInTransaction(pgConn) {
-		err := execSimple(ctx, pgConn, "ROLLBACK;")
+	timeoutCtx, cancel := withMigrationTimeout(ctx, session.Timeout)
+	defer cancel()
 
-		return &TransactionNotCommittedError{RollBackError: err}
-	}
+	transaction, execution := applier.transactionMode(source), applier.executionMode(source)
 
-	return nil
+	err = execMigration(timeoutCtx, applier.connection.PgConn(), sql, transaction, execution, beforeCommit)
+
+	return timeoutError(ctx, timeoutCtx, session.Timeout, err)
 }
 
 func (applier *applier) registerMigration(
@@ -256,46 +731,19 @@ func (applier *applier) registerMigration(
 		return nil
 	}
 
-	migration := &Migration{
-		ID:              ref.id,
-		Name:            source.Configuration.Name,
-		AppliedAt:       time.Now().UTC(),
-		SQLUp:           source.UpSQL,
-		SQLDown:         source.DownSQL,
-		SQLUpSHA256:     Sha256ToHexStr(source.UpSQL),
-		SQLDownSHA256:   Sha256ToHexStr(source.DownSQL),
-		DurationMs:      duration.Milliseconds(),
-		RollbackBlocked: source.Configuration.Down.Block,
-		Meta:            source.Configuration.Meta,
-	}
+	migration := NewMigration(ref.id, source, duration)
 
 	return applier.migrationsRepo.Insert(ctx, applier.connection, migration)
 }
 
 func (applier *applier) connect(ctx context.Context) error {
-	applier.connection = nil
-
-	connection, err := pgx.Connect(ctx, applier.databaseURL)
-
-	if err != nil {
-		return err //nolint:wrapcheck
-	}
+	connection, err := connectWithNotices(ctx, applier.databaseURL, applier.searchPath, applier.handleNotice)
 
 	applier.connection = connection
 
-	return nil
+	return err
 }
 
 func (applier *applier) close(ctx context.Context) error {
-	if applier.connection == nil {
-		return nil
-	}
-
-	err := applier.connection.Close(ctx)
-
-	if err != nil {
-		log.Println("Failed to close the database connection:", err)
-	}
-
-	return err //nolint:wrapcheck
+	return errors.Join(closeConnection(ctx, applier.connection), closeConnection(ctx, applier.lockConnection))
 }
@@ -20,19 +20,9 @@ func TestApplyPending(t *testing.T) {
 	connectionURL := tests.StartEmbeddedPostgres(t)
 	conn := tests.OpenPgConnection(t, connectionURL)
 	dir := t.TempDir()
-	report := migrator.Report{PendingCount: 0}
-
-	options := migrator.ApplyOptions{
-		MaxSQLFileSize: 1024,
-		Project: project.Project{
-			Dir:           dir,
-			Configuration: createProjectConfig(),
-		},
-		DatabaseURL:       string(connectionURL),
-		Limit:             migrator.NoLimit,
-		DryRun:            false,
-		SkipPreValidation: false,
-	}
+	report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+
+	options := newApplyOptions(connectionURL, dir)
 
 	mustApplyPending := func(t *testing.T) {
 		t.Helper()
@@ -204,6 +194,7 @@ func TestApplyPending(t *testing.T) {
 		dir := t.TempDir()
 		preValidateOptions := options
 		preValidateOptions.Project.Dir = dir
+		preValidateOptions.Project.Configuration.MigrationsTableName = "pre_validation_migrations"
 
 		source1 := tests.CreateSource(t, dir, "Valid migration 1", "20250122110947")
 		source2 := tests.CreateSource(t, dir, "Valid migration 2", "20250318142235")
@@ -264,6 +255,7 @@ func TestApplyPending(t *testing.T) {
 
 		optionsCopy := options
 		optionsCopy.Project.Dir = dir
+		optionsCopy.Project.Configuration.MigrationsTableName = "limit_migrations"
 		optionsCopy.Limit = 1
 
 		err := migrator.ApplyPending(t.Context(), optionsCopy, &report)
@@ -279,10 +271,88 @@ func TestApplyPending(t *testing.T) {
 		tests.AssertPgTableExist(t, conn, "limit_2")
 		assert.Equal(t, 1, report.PendingCount)
 	})
+
+	t.Run("To", func(t *testing.T) {
+		dir := t.TempDir()
+		source1 := tests.CreateSource(t, dir, "Target 1", "20250414193712")
+		source2 := tests.CreateSource(t, dir, "Target 2", "20250415193712")
+		source3 := tests.CreateSource(t, dir, "Target 3", "20250416193712")
+
+		writeUpSQL(t, source1.FullPath, "CREATE TABLE to_1 (id INTEGER);")
+		writeUpSQL(t, source2.FullPath, "CREATE TABLE to_2 (id INTEGER);")
+		writeUpSQL(t, source3.FullPath, "CREATE TABLE to_3 (id INTEGER);")
+
+		optionsCopy := options
+		optionsCopy.Project.Dir = dir
+		optionsCopy.Project.Configuration.MigrationsTableName = "to_migrations"
+
+		t.Run("It fails when the ID does not exist on disk", func(t *testing.T) {
+			optionsCopy := optionsCopy
+			optionsCopy.ToID = source.NewIDFromString("20250414193713")
+
+			err := migrator.ApplyPending(t.Context(), optionsCopy, &report)
+
+			var notFoundErr *migrator.SourceNotFoundError
+
+			require.ErrorAs(t, err, &notFoundErr)
+			tests.AssertPgTableNotExist(t, conn, "to_1")
+		})
+
+		t.Run("In dry run mode, it applies nothing", func(t *testing.T) {
+			optionsCopy := optionsCopy
+			optionsCopy.ToID = source.NewIDFromString("20250415193712")
+			optionsCopy.DryRun = true
+
+			require.NoError(t, migrator.ApplyPending(t.Context(), optionsCopy, &report))
+			assert.Equal(t, 2, report.PendingCount)
+			tests.AssertPgTableNotExist(t, conn, "to_1")
+		})
+
+		t.Run("It combines with the limit", func(t *testing.T) {
+			optionsCopy := optionsCopy
+			optionsCopy.ToID = source.NewIDFromString("20250415193712")
+			optionsCopy.Limit = 1
+
+			require.NoError(t, migrator.ApplyPending(t.Context(), optionsCopy, &report))
+			assert.Equal(t, 1, report.PendingCount)
+			tests.AssertPgTableExist(t, conn, "to_1")
+			tests.AssertPgTableNotExist(t, conn, "to_2")
+		})
+
+		t.Run("It applies migrations up to and including the ID", func(t *testing.T) {
+			optionsCopy := optionsCopy
+			optionsCopy.ToID = source.NewIDFromString("20250415193712")
+
+			require.NoError(t, migrator.ApplyPending(t.Context(), optionsCopy, &report))
+			assert.Equal(t, 1, report.PendingCount)
+			tests.AssertPgTableExist(t, conn, "to_2")
+			tests.AssertPgTableNotExist(t, conn, "to_3")
+		})
+	})
+}
+
+// newApplyOptions returns the options of a plain 'andmerada migrate' of the project in dir.
+// Tests override only the fields they care about.
+func newApplyOptions(connectionURL tests.ConnectionURL, dir string) migrator.ApplyOptions {
+	return migrator.ApplyOptions{
+		MaxSQLFileSize:    1024,
+		DatabaseURL:       string(connectionURL),
+		Project:           project.Project{Dir: dir, Configuration: createProjectConfig(), Callbacks: nil},
+		Limit:             migrator.NoLimit,
+		ToID:              source.EmptyMigrationID,
+		DryRun:            false,
+		Rehearse:          false,
+		SkipPreValidation: false,
+		AllowDrift:        false,
+		LockTimeout:       migrator.DefaultLockTimeout,
+		OutOfOrder:        "",
+		Filter:            "",
+		Placeholders:      nil,
+	}
 }
 
 func createProjectConfig() project.Configuration {
-	return project.Configuration{
+	return project.Configuration{ //nolint:exhaustruct
 		MigrationsTableName: "migrations",
 	}
 }
@@ -0,0 +1,273 @@
+package migrator
+
+import (
+	"cmp"
+	"context"
+	"log"
+	"maps"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/servletcloud/Andmerada/internal/project"
+	"github.com/servletcloud/Andmerada/internal/settings"
+	"github.com/servletcloud/Andmerada/internal/source"
+)
+
+const (
+	MetaKeyBaselined = "baselined"
+)
+
+type BaselineOptions struct {
+	MaxSQLFileSize int64
+	DatabaseURL    string
+	Project        project.Project
+	ID             source.ID
+	LockTimeout    time.Duration
+}
+
+type BaselineReport struct {
+	MarkedIDs []source.ID
+}
+
+type baseliner struct {
+	databaseURL string
+	projectDir  string
+	searchPath  []string
+	id          source.ID
+	lockTimeout time.Duration
+	selfUpgrade settings.SelfUpgradeMode
+
+	report         *BaselineReport
+	migrationsRepo *Migrations
+	lock           *Lock
+	loader         source.Loader
+	connection     *pgx.Conn
+
+	sourceIDToName map[source.ID]string
+	appliedIDs     map[source.ID]bool
+}
+
+func Baseline(ctx context.Context, options BaselineOptions, report *BaselineReport) error {
+	return runBaseliner(ctx, options, report, (*baseliner).baseline)
+}
+
+func MarkApplied(ctx context.Context, options BaselineOptions, report *BaselineReport) error {
+	return runBaseliner(ctx, options, report, (*baseliner).markApplied)
+}
+
+func MarkPending(ctx context.Context, options BaselineOptions, report *BaselineReport) error {
+	return runBaseliner(ctx, options, report, (*baseliner).markPending)
+}
+
+func runBaseliner(
+	ctx context.Context,
+	options BaselineOptions,
+	report *BaselineReport,
+	action func(baseliner *baseliner, ctx context.Context) error,
+) error {
+	migrationsTable := options.Project.Configuration.MigrationsTableName
+
+	report.MarkedIDs = nil
+
+	baseliner := &baseliner{
+		databaseURL:    options.DatabaseURL,
+		projectDir:     options.Project.Dir,
+		searchPath:     options.Project.Configuration.SearchPath,
+		id:             options.ID,
+		lockTimeout:    options.LockTimeout,
+		selfUpgrade:    options.Project.Configuration.SelfUpgrade,
+		report:         report,
+		migrationsRepo: &Migrations{TableName: migrationsTable, CreateSchema: options.Project.Configuration.CreateSchema},
+		lock:           &Lock{TableName: migrationsTable},
+		loader:         source.Loader{MaxSQLFileSize: options.MaxSQLFileSize},
+		connection:     nil,
+		sourceIDToName: nil,
+		appliedIDs:     nil,
+	}
+
+	defer baseliner.close(ctx)
+
+	if err := baseliner.prepare(ctx); err != nil {
+		return err
+	}
+
+	defer baseliner.releaseLock(ctx)
+
+	return action(baseliner, ctx)
+}
+
+func (baseliner *baseliner) prepare(ctx context.Context) error {
+	sourceIDToName, err := source.ScanAll(baseliner.projectDir)
+	if err != nil {
+		return wrapError(err, ErrTypeListMigrationsOnDisk)
+	}
+
+	baseliner.sourceIDToName = sourceIDToName
+
+	if err := baseliner.connect(ctx); err != nil {
+		return wrapError(err, ErrTypeDBConnect)
+	}
+
+	if err := baseliner.lock.Acquire(ctx, baseliner.connection, baseliner.lockTimeout); err != nil {
+		return wrapError(err, ErrTypeAcquireLock)
+	}
+
+	if err := prepareSchema(ctx, baseliner.connection, baseliner.migrationsRepo, baseliner.selfUpgrade); err != nil {
+		return err
+	}
+
+	applied, err := baseliner.migrationsRepo.ListApplied(ctx, baseliner.connection)
+	if err != nil {
+		return wrapError(err, ErrTypeScanAppliedMigrations)
+	}
+
+	baseliner.appliedIDs = make(map[source.ID]bool, len(applied))
+
+	for _, migration := range applied {
+		baseliner.appliedIDs[migration.ID] = true
+	}
+
+	return nil
+}
+
+func (baseliner *baseliner) baseline(ctx context.Context) error {
+	if _, found := baseliner.sourceIDToName[baseliner.id]; !found {
+		return wrapError(&SourceNotFoundError{ID: baseliner.id}, ErrTypeBaselineTarget)
+	}
+
+	ids := slices.SortedFunc(maps.Keys(baseliner.sourceIDToName), cmp.Compare)
+
+	return baseliner.inTransaction(ctx, func() error {
+		for _, id := range ids {
+			if id > baseliner.id {
+				break
+			}
+
+			if baseliner.appliedIDs[id] {
+				continue
+			}
+
+			if err := baseliner.register(ctx, id); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (baseliner *baseliner) markApplied(ctx context.Context) error {
+	id := baseliner.id
+
+	if _, found := baseliner.sourceIDToName[id]; !found {
+		return wrapError(&SourceNotFoundError{ID: id}, ErrTypeBaselineTarget)
+	}
+
+	if baseliner.appliedIDs[id] {
+		return wrapError(&AlreadyAppliedError{ID: id}, ErrTypeBaselineTarget)
+	}
+
+	return baseliner.inTransaction(ctx, func() error {
+		return baseliner.register(ctx, id)
+	})
+}
+
+func (baseliner *baseliner) markPending(ctx context.Context) error {
+	id := baseliner.id
+
+	if !baseliner.appliedIDs[id] {
+		return wrapError(&NotAppliedError{ID: id}, ErrTypeBaselineTarget)
+	}
+
+	if err := baseliner.migrationsRepo.Delete(ctx, baseliner.connection, id); err != nil {
+		return wrapError(err, ErrTypeUnregisterMigration)
+	}
+
+	log.Printf("Marked %v as pending", id)
+
+	baseliner.report.MarkedIDs = append(baseliner.report.MarkedIDs, id)
+
+	return nil
+}
+
+func (baseliner *baseliner) register(ctx context.Context, id source.ID) error {
+	name := baseliner.sourceIDToName[id]
+	source := source.Source{} //nolint:exhaustruct
+
+	if err := baseliner.loader.LoadSource(filepath.Join(baseliner.projectDir, name), &source); err != nil {
+		return wrapError(&LoadSourceError{Cause: err, Name: name}, ErrTypeLoadMigration)
+	}
+
+	migration := NewMigration(id, &source, 0)
+	migration.Meta = maps.Clone(migration.Meta)
+
+	if migration.Meta == nil {
+		migration.Meta = make(map[string]any)
+	}
+
+	migration.Meta[MetaKeyBaselined] = true
+
+	if err := baseliner.migrationsRepo.Insert(ctx, baseliner.connection, migration); err != nil {
+		return wrapError(err, ErrTypeRegisterMigration)
+	}
+
+	log.Printf("Marked %q as applied", name)
+
+	baseliner.report.MarkedIDs = append(baseliner.report.MarkedIDs, id)
+
+	return nil
+}
+
+// inTransaction runs action between BEGIN and COMMIT, so either all migrations are marked or none of them.
+func (baseliner *baseliner) inTransaction(ctx context.Context, action func() error) error {
+	conn := baseliner.connection.PgConn()
+
+	if err := execSimple(ctx, conn, "BEGIN;"); err != nil {
+		return wrapError(&ExecSQLError{Cause: err, SQL: "BEGIN;"}, ErrTypeRegisterMigration)
+	}
+
+	if err := action(); err != nil {
+		if rollbackErr := execSimple(ctx, conn, "ROLLBACK;"); rollbackErr != nil {
+			log.Println("Failed to roll back marking the migrations:", rollbackErr)
+		}
+
+		log.Println("Rolled back, no migrations are marked as applied")
+
+		baseliner.report.MarkedIDs = nil
+
+		return err
+	}
+
+	if err := execSimple(ctx, conn, "COMMIT;"); err != nil {
+		baseliner.report.MarkedIDs = nil
+
+		return wrapError(&ExecSQLError{Cause: err, SQL: "COMMIT;"}, ErrTypeRegisterMigration)
+	}
+
+	return nil
+}
+
+func (baseliner *baseliner) releaseLock(ctx context.Context) {
+	if err := baseliner.lock.Release(ctx, baseliner.connection); err != nil {
+		log.Println("Failed to release the migrations lock:", err)
+	}
+}
+
+func (baseliner *baseliner) connect(ctx context.Context) error {
+	connection, err := connect(ctx, baseliner.databaseURL, baseliner.searchPath)
+
+	baseliner.connection = connection
+
+	if err != nil {
+		return err
+	}
+
+	// The connection holds the migrations lock.
+	return setApplicationName(ctx, connection, "baseline")
+}
+
+func (baseliner *baseliner) close(ctx context.Context) error {
+	return closeConnection(ctx, baseliner.connection)
+}
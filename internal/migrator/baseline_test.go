@@ -0,0 +1,132 @@
+package migrator_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/servletcloud/Andmerada/internal/migrator"
+	"github.com/servletcloud/Andmerada/internal/project"
+	"github.com/servletcloud/Andmerada/internal/source"
+	"github.com/servletcloud/Andmerada/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:paralleltest,funlen
+func TestBaseline(t *testing.T) {
+	connectionURL := tests.StartEmbeddedPostgres(t)
+	conn := tests.OpenPgConnection(t, connectionURL)
+	dir := t.TempDir()
+	testProject := project.Project{Dir: dir, Configuration: createProjectConfig(), Callbacks: nil}
+
+	options := migrator.BaselineOptions{
+		MaxSQLFileSize: 1024,
+		DatabaseURL:    string(connectionURL),
+		Project:        testProject,
+		ID:             20250401101011,
+		LockTimeout:    migrator.DefaultLockTimeout,
+	}
+
+	for _, timestamp := range []string{"20250401101010", "20250401101011", "20250401101012"} {
+		result := tests.CreateSource(t, dir, "Migration "+timestamp, timestamp)
+		writeUpSQL(t, result.FullPath, "CREATE TABLE table_"+timestamp+" (id INTEGER);")
+	}
+
+	t.Run("Fails when the target ID is not on disk", func(t *testing.T) {
+		optionsCopy := options
+		optionsCopy.ID = 20250401101099
+
+		report := migrator.BaselineReport{MarkedIDs: nil}
+		err := migrator.Baseline(t.Context(), optionsCopy, &report)
+
+		var notFoundErr *migrator.SourceNotFoundError
+
+		require.ErrorAs(t, err, &notFoundErr)
+		assert.Empty(t, report.MarkedIDs)
+	})
+
+	t.Run("Marks nothing when one of the migrations cannot be loaded", func(t *testing.T) {
+		brokenDir := t.TempDir()
+
+		for _, timestamp := range []string{"20250402101010", "20250402101011"} {
+			result := tests.CreateSource(t, brokenDir, "Migration "+timestamp, timestamp)
+			writeUpSQL(t, result.FullPath, "SELECT 1;")
+		}
+
+		broken := tests.CreateSource(t, brokenDir, "Broken", "20250402101012")
+		require.NoError(t, os.Remove(filepath.Join(broken.FullPath, source.UpSQLFilename)))
+
+		optionsCopy := options
+		optionsCopy.Project.Dir = brokenDir
+		optionsCopy.Project.Configuration.MigrationsTableName = "broken_migrations"
+		optionsCopy.ID = 20250402101012
+
+		report := migrator.BaselineReport{MarkedIDs: nil}
+		err := migrator.Baseline(t.Context(), optionsCopy, &report)
+
+		var loadErr *migrator.LoadSourceError
+
+		require.ErrorAs(t, err, &loadErr)
+		assert.Empty(t, report.MarkedIDs)
+
+		var count int
+
+		require.NoError(t, conn.QueryRow(t.Context(), "SELECT COUNT(*) FROM broken_migrations").Scan(&count))
+		assert.Zero(t, count)
+	})
+
+	t.Run("Marks migrations up to the target ID as applied without running them", func(t *testing.T) {
+		report := migrator.BaselineReport{MarkedIDs: nil}
+		require.NoError(t, migrator.Baseline(t.Context(), options, &report))
+
+		assert.Equal(t, []source.ID{20250401101010, 20250401101011}, report.MarkedIDs)
+		tests.AssertPgTableNotExist(t, conn, "table_20250401101010")
+
+		var (
+			durationMs int64
+			meta       map[string]any
+		)
+
+		row := conn.QueryRow(t.Context(), "SELECT duration_ms, meta FROM migrations WHERE id = 20250401101010")
+		require.NoError(t, row.Scan(&durationMs, &meta))
+
+		assert.Zero(t, durationMs)
+		assert.Equal(t, true, meta[migrator.MetaKeyBaselined])
+	})
+
+	t.Run("Migrate applies only the remaining migrations", func(t *testing.T) {
+		applyOptions := newApplyOptions(connectionURL, dir)
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+
+		require.NoError(t, migrator.ApplyPending(t.Context(), applyOptions, &report))
+
+		assert.Equal(t, 1, report.PendingCount)
+		tests.AssertPgTableExist(t, conn, "table_20250401101012")
+	})
+
+	t.Run("Mark pending and mark applied", func(t *testing.T) {
+		optionsCopy := options
+		optionsCopy.ID = 20250401101012
+
+		report := migrator.BaselineReport{MarkedIDs: nil}
+
+		require.NoError(t, migrator.MarkPending(t.Context(), optionsCopy, &report))
+		assertMigrationNotRegistered(t, conn, 20250401101012)
+		tests.AssertPgTableExist(t, conn, "table_20250401101012")
+
+		err := migrator.MarkPending(t.Context(), optionsCopy, &report)
+
+		var notAppliedErr *migrator.NotAppliedError
+
+		require.ErrorAs(t, err, &notAppliedErr)
+
+		require.NoError(t, migrator.MarkApplied(t.Context(), optionsCopy, &report))
+
+		err = migrator.MarkApplied(t.Context(), optionsCopy, &report)
+
+		var alreadyAppliedErr *migrator.AlreadyAppliedError
+
+		require.ErrorAs(t, err, &alreadyAppliedErr)
+	})
+}
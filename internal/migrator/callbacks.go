@@ -0,0 +1,111 @@
+package migrator
+
+import (
+	"context"
+	"log"
+
+	"github.com/servletcloud/Andmerada/internal/placeholder"
+	"github.com/servletcloud/Andmerada/internal/project"
+	"github.com/servletcloud/Andmerada/internal/source"
+)
+
+// Session variables that expose the current migration to before_each, after_each and after_error,
+// e.g. SELECT current_setting('andmerada.migration_id').
+const (
+	migrationIDVariable   = "andmerada.migration_id"
+	migrationNameVariable = "andmerada.migration_name"
+)
+
+// loadCallbacks reads the SQL of the project callbacks upfront, so that a broken callback
+// fails the run before any migration is applied.
+func (applier *applier) loadCallbacks() error {
+	values := projectPlaceholders(applier.placeholders, applier.migrationsTable)
+
+	for callback, path := range applier.callbackPaths {
+		sql, err := applier.loader.LoadSQLFile(path)
+		if err != nil {
+			return &CallbackError{Callback: callback, Cause: err}
+		}
+
+		if sql, err = placeholder.Replace(sql, values); err != nil {
+			return &CallbackError{Callback: callback, Cause: err}
+		}
+
+		applier.callbacks[callback] = sql
+	}
+
+	return nil
+}
+
+func (applier *applier) hasCallback(callback project.Callback) bool {
+	_, found := applier.callbacks[callback]
+
+	return found && !applier.dryRun
+}
+
+// runCallback executes the SQL of the callback on the connection of the migrations.
+// It does nothing when the project has no such callback.
+func (applier *applier) runCallback(ctx context.Context, callback project.Callback) error {
+	if !applier.hasCallback(callback) {
+		return nil
+	}
+
+	log.Printf("Running callback %s...", callback)
+
+	if err := execMigrationSQL(ctx, applier.connection.PgConn(), applier.callbacks[callback]); err != nil {
+		return &CallbackError{Callback: callback, Cause: err}
+	}
+
+	return nil
+}
+
+// runMigrationCallback sets the ID and name of the migration as session variables
+// and then executes the callback.
+func (applier *applier) runMigrationCallback(
+	ctx context.Context,
+	callback project.Callback,
+	ref sourceRef,
+	source *source.Source,
+) error {
+	if !applier.hasCallback(callback) {
+		return nil
+	}
+
+	query := "SELECT set_config($1, $2, false), set_config($3, $4, false)"
+
+	_, err := applier.connection.Exec(ctx, query,
+		migrationIDVariable, ref.id.String(),
+		migrationNameVariable, source.Configuration.Name,
+	)
+	if err != nil {
+		return &CallbackError{Callback: callback, Cause: &ExecSQLError{Cause: err, SQL: query}}
+	}
+
+	return applier.runCallback(ctx, callback)
+}
+
+// runAfterErrorCallback executes after_error once a migration failed. Its own failure is only
+// logged, so that the error of the migration is the one reported.
+func (applier *applier) runAfterErrorCallback(ctx context.Context, ref sourceRef, source *source.Source) {
+	if !applier.hasCallback(project.CallbackAfterError) {
+		return
+	}
+
+	if applier.connection.IsClosed() {
+		log.Printf("Skipped callback %s: the database connection is closed.", project.CallbackAfterError)
+
+		return
+	}
+
+	if isConnectionInTransaction(applier.connection.PgConn()) {
+		if err := execSimple(ctx, applier.connection.PgConn(), "ROLLBACK;"); err != nil {
+			log.Printf("Skipped callback %s: %v", project.CallbackAfterError, err)
+
+			return
+		}
+	}
+
+	if err := applier.runMigrationCallback(ctx, project.CallbackAfterError, ref, source); err != nil {
+		log.Println("Warning:", err)
+	}
+}
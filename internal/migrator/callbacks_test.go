@@ -0,0 +1,120 @@
+package migrator_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/servletcloud/Andmerada/internal/migrator"
+	"github.com/servletcloud/Andmerada/internal/osutil"
+	"github.com/servletcloud/Andmerada/internal/project"
+	"github.com/servletcloud/Andmerada/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:paralleltest,funlen
+func TestApplyPendingCallbacks(t *testing.T) {
+	connectionURL := tests.StartEmbeddedPostgres(t)
+	conn := tests.OpenPgConnection(t, connectionURL)
+	dir := t.TempDir()
+	callbacksDir := filepath.Join(dir, project.CallbacksDirName)
+
+	require.NoError(t, os.Mkdir(callbacksDir, osutil.DirPerm0755))
+
+	_, err := conn.Exec(t.Context(), "CREATE TABLE callback_log (seq SERIAL, event TEXT, migration TEXT);")
+	require.NoError(t, err)
+
+	logEvent := func(event string) string {
+		return "INSERT INTO callback_log (event, migration) VALUES ('" + event + "', " +
+			"current_setting('andmerada.migration_id', true) || ' ' || current_setting('andmerada.migration_name', true));"
+	}
+
+	callbacks := project.Callbacks{}
+
+	for _, callback := range []project.Callback{
+		project.CallbackBeforeMigrate,
+		project.CallbackBeforeEach,
+		project.CallbackAfterEach,
+		project.CallbackAfterMigrate,
+		project.CallbackAfterError,
+	} {
+		path := filepath.Join(callbacksDir, string(callback)+".sql")
+		require.NoError(t, os.WriteFile(path, []byte(logEvent(string(callback))), osutil.FilePerm0644))
+
+		callbacks[callback] = path
+	}
+
+	options := newApplyOptions(connectionURL, dir)
+	options.Project.Callbacks = callbacks
+
+	readLog := func(t *testing.T) []string {
+		t.Helper()
+
+		query := "SELECT event || ': ' || COALESCE(migration, '') FROM callback_log ORDER BY seq"
+
+		rows, err := conn.Query(t.Context(), query)
+		require.NoError(t, err)
+
+		events, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		require.NoError(t, err)
+
+		_, err = conn.Exec(t.Context(), "TRUNCATE callback_log;")
+		require.NoError(t, err)
+
+		return events
+	}
+
+	first := tests.CreateSource(t, dir, "Create accounts", "20251201101010")
+	writeUpSQL(t, first.FullPath, "CREATE TABLE accounts (id INTEGER);")
+
+	t.Run("Callbacks run around the migrations", func(t *testing.T) {
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+		require.NoError(t, migrator.ApplyPending(t.Context(), options, &report))
+
+		expected := []string{
+			"before_migrate: ",
+			"before_each: 20251201101010 Create accounts",
+			"after_each: 20251201101010 Create accounts",
+			"after_migrate: 20251201101010 Create accounts",
+		}
+		assert.Equal(t, expected, readLog(t))
+	})
+
+	t.Run("Callbacks do not run without pending migrations", func(t *testing.T) {
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+		require.NoError(t, migrator.ApplyPending(t.Context(), options, &report))
+
+		assert.Empty(t, readLog(t))
+	})
+
+	t.Run("after_error runs when a migration fails", func(t *testing.T) {
+		failed := tests.CreateSource(t, dir, "Broken", "20251202101010")
+		writeUpSQL(t, failed.FullPath, "BEGIN;\nINSERT INTO missing VALUES (1);\nCOMMIT;")
+
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+		require.Error(t, migrator.ApplyPending(t.Context(), options, &report))
+
+		expected := []string{
+			"before_migrate: ",
+			"before_each: 20251202101010 Broken",
+			"after_error: 20251202101010 Broken",
+		}
+		assert.Equal(t, expected, readLog(t))
+	})
+
+	t.Run("A failed callback fails the run", func(t *testing.T) {
+		path := callbacks[project.CallbackBeforeEach]
+		require.NoError(t, os.WriteFile(path, []byte("SELECT * FROM missing;"), osutil.FilePerm0644))
+
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+		err := migrator.ApplyPending(t.Context(), options, &report)
+
+		var callbackErr *migrator.CallbackError
+
+		require.ErrorAs(t, err, &callbackErr)
+		assert.Equal(t, project.CallbackBeforeEach, callbackErr.Callback)
+		assert.Equal(t, migrator.EntryFailed, report.Entries[0].Status)
+	})
+}
@@ -2,7 +2,12 @@ package migrator
 
 import (
 	"errors"
+	"fmt"
 	"strings"
+	"time"
+
+	"github.com/servletcloud/Andmerada/internal/project"
+	"github.com/servletcloud/Andmerada/internal/source"
 )
 
 type ErrType int
@@ -16,6 +21,24 @@ const (
 	ErrTypeLoadMigration
 	ErrTypeApplyMigration
 	ErrTypeRegisterMigration
+	ErrTypeRollbackTarget
+	ErrTypeRollbackBlocked
+	ErrTypeRollbackMigration
+	ErrTypeUnregisterMigration
+	ErrTypeChecksumDrift
+	ErrTypeAcquireLock
+	ErrTypeQueryLock
+	ErrTypeBaselineTarget
+	ErrTypeOutOfOrder
+	ErrTypeFilterMigrations
+	ErrTypeMigrateTarget
+	ErrTypeRehearsal
+	ErrTypeListHistory
+	ErrTypeSchemaVersion
+	ErrTypeCallback
+	ErrTypeIsolateSession
+	ErrTypeWarning
+	ErrTypeLockLost
 )
 
 func wrapError(err error, errType ErrType) error {
@@ -54,6 +77,37 @@ func (e *ExecSQLError) Unwrap() error {
 	return e.Cause
 }
 
+// StatementError is a failure of one statement of a migration that runs in the statement execution mode.
+// Line is the line of the file on which the statement starts.
+type StatementError struct {
+	Cause  error
+	Number int
+	Count  int
+	Line   int
+}
+
+func (e *StatementError) Error() string {
+	return fmt.Sprintf("statement %d, file line %d: %v", e.Number, e.Line, e.Cause)
+}
+
+func (e *StatementError) Unwrap() error {
+	return e.Cause
+}
+
+// MigrationTimeoutError is returned when an attempt to apply a migration exceeds the timeout of its session.
+type MigrationTimeoutError struct {
+	Cause   error
+	Timeout time.Duration
+}
+
+func (e *MigrationTimeoutError) Error() string {
+	return fmt.Sprintf("the migration did not finish within the timeout of %v: %v", e.Timeout, e.Cause)
+}
+
+func (e *MigrationTimeoutError) Unwrap() error {
+	return e.Cause
+}
+
 type ApplyMigrationError struct {
 	Cause error
 	Name  string
@@ -101,3 +155,249 @@ func (e *LoadSourceError) Error() string {
 func (e *LoadSourceError) Unwrap() error {
 	return e.Cause
 }
+
+type RollbackMigrationError struct {
+	Cause error
+	ID    source.ID
+	Name  string
+}
+
+func (e *RollbackMigrationError) Error() string {
+	return e.Cause.Error()
+}
+
+func (e *RollbackMigrationError) Unwrap() error {
+	return e.Cause
+}
+
+type RollbackTargetNotAppliedError struct {
+	ID source.ID
+}
+
+func (e *RollbackTargetNotAppliedError) Error() string {
+	return fmt.Sprintf("migration %v is not applied, cannot roll back to it", e.ID)
+}
+
+type BlockedRollback struct {
+	ID     source.ID
+	Name   string
+	Reason string
+}
+
+type RollbackBlockedError struct {
+	Blocked []BlockedRollback
+}
+
+func (e *RollbackBlockedError) Error() string {
+	var sb strings.Builder
+
+	sb.WriteString("rollback is blocked for the following migrations:")
+
+	for _, blocked := range e.Blocked {
+		fmt.Fprintf(&sb, "\n  - %v %q: %s", blocked.ID, blocked.Name, blocked.Reason)
+	}
+
+	return sb.String()
+}
+
+type SourceNotFoundError struct {
+	ID source.ID
+}
+
+func (e *SourceNotFoundError) Error() string {
+	return fmt.Sprintf("migration %v is not found on disk", e.ID)
+}
+
+type ChecksumDriftError struct {
+	Drifts []Drift
+}
+
+func (e *ChecksumDriftError) Error() string {
+	var sb strings.Builder
+
+	sb.WriteString("applied migrations differ from the files on disk:")
+
+	for _, drift := range e.Drifts {
+		fmt.Fprintf(&sb, "\n  - %v %q is %v. %s", drift.ID, drift.Name, drift.Kind, drift.Details)
+	}
+
+	return sb.String()
+}
+
+type LockTimeoutError struct {
+	Timeout time.Duration
+	Holders []LockHolder
+}
+
+func (e *LockTimeoutError) Error() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "could not acquire the migrations lock within %v", e.Timeout)
+
+	if len(e.Holders) == 0 {
+		sb.WriteString(", the lock was released by its holder in the meantime")
+	}
+
+	for _, holder := range e.Holders {
+		fmt.Fprintf(&sb, "\n  - held by PID %d, application %q, user %q, client %q, connected since %v",
+			holder.PID,
+			holder.ApplicationName,
+			holder.UserName,
+			holder.ClientAddr,
+			holder.BackendStart.Format(time.RFC3339),
+		)
+	}
+
+	return sb.String()
+}
+
+type AlreadyAppliedError struct {
+	ID source.ID
+}
+
+func (e *AlreadyAppliedError) Error() string {
+	return fmt.Sprintf("migration %v is already applied", e.ID)
+}
+
+type NotAppliedError struct {
+	ID source.ID
+}
+
+func (e *NotAppliedError) Error() string {
+	return fmt.Sprintf("migration %v is not applied", e.ID)
+}
+
+type ManagedTransactionError struct {
+	Statement string
+	Line      int
+	Reason    string
+}
+
+func (e *ManagedTransactionError) Error() string {
+	return fmt.Sprintf(
+		"%s, line %d: %s\nRemove the statement or set `transaction: none` in migration.yml",
+		e.Reason, e.Line, e.Statement,
+	)
+}
+
+type OutOfOrderError struct {
+	IDs             []source.ID
+	LatestAppliedID source.ID
+}
+
+func (e *OutOfOrderError) Error() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%d pending migration(s) are older than the latest applied migration %v:", len(e.IDs), e.LatestAppliedID)
+
+	for _, id := range e.IDs {
+		fmt.Fprintf(&sb, "\n  - %v", id)
+	}
+
+	return sb.String()
+}
+
+type RehearsalFailedError struct {
+	Failed []ReportEntry
+	Total  int
+}
+
+func (e *RehearsalFailedError) Error() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%d of %d rehearsed migration(s) failed:", len(e.Failed), e.Total)
+
+	for _, entry := range e.Failed {
+		fmt.Fprintf(&sb, "\n  - %v %q: %v", entry.ID, entry.Name, entry.Err)
+	}
+
+	return sb.String()
+}
+
+type SchemaTooNewError struct {
+	Version   int
+	Supported int
+}
+
+func (e *SchemaTooNewError) Error() string {
+	return fmt.Sprintf(
+		"the bookkeeping schema is at version %d, but this andmerada supports versions up to %d",
+		e.Version, e.Supported,
+	)
+}
+
+type SchemaUpgradeRequiredError struct {
+	Version int
+	Latest  int
+}
+
+func (e *SchemaUpgradeRequiredError) Error() string {
+	return fmt.Sprintf(
+		"the bookkeeping schema is at version %d, but this andmerada requires version %d",
+		e.Version, e.Latest,
+	)
+}
+
+type CallbackError struct {
+	Callback project.Callback
+	Cause    error
+}
+
+func (e *CallbackError) Error() string {
+	return fmt.Sprintf("callback %s failed: %v", e.Callback, e.Cause)
+}
+
+func (e *CallbackError) Unwrap() error {
+	return e.Cause
+}
+
+type AlreadyRegisteredError struct {
+	Name string
+}
+
+func (e *AlreadyRegisteredError) Error() string {
+	return fmt.Sprintf("migration %q is already registered after the connection was lost, it is not retried", e.Name)
+}
+
+type LockLostError struct {
+	Cause error
+}
+
+func (e *LockLostError) Error() string {
+	return fmt.Sprintf("the connection that holds the migrations lock was lost: %v", e.Cause)
+}
+
+func (e *LockLostError) Unwrap() error {
+	return e.Cause
+}
+
+type IsolateSessionError struct {
+	Cause error
+	Name  string
+}
+
+func (e *IsolateSessionError) Error() string {
+	return fmt.Sprintf("cannot isolate the session after migration %q: %v", e.Name, e.Cause)
+}
+
+func (e *IsolateSessionError) Unwrap() error {
+	return e.Cause
+}
+
+// WarningError fails the run with `fail_on_warning`. RolledBack is set when the migration was undone,
+// which is not possible for a migration with `transaction: none` that is already committed.
+type WarningError struct {
+	Name       string
+	Warnings   []Notice
+	RolledBack bool
+}
+
+func (e *WarningError) Error() string {
+	outcome := "it was rolled back"
+	if !e.RolledBack {
+		outcome = "it stays applied because its transaction mode is none"
+	}
+
+	return fmt.Sprintf("migration %q raised %d warning(s), %s: %s",
+		e.Name, len(e.Warnings), outcome, e.Warnings[0].Message)
+}
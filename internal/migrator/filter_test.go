@@ -0,0 +1,61 @@
+package migrator_test
+
+import (
+	"testing"
+
+	"github.com/servletcloud/Andmerada/internal/migrator"
+	"github.com/servletcloud/Andmerada/internal/source"
+	"github.com/servletcloud/Andmerada/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:paralleltest
+func TestApplyPendingFilter(t *testing.T) {
+	connectionURL := tests.StartEmbeddedPostgres(t)
+	conn := tests.OpenPgConnection(t, connectionURL)
+	dir := t.TempDir()
+
+	soaked := tests.CreateSource(t, dir, "Soaked", "20250101101010")
+	writeUpSQL(t, soaked.FullPath, "CREATE TABLE soaked (id INTEGER);")
+
+	fresh := tests.CreateSource(t, dir, "Fresh", "20250102101010")
+	writeUpSQL(t, fresh.FullPath, "CREATE TABLE fresh (id INTEGER);")
+
+	options := newFilterApplyOptions(dir, "sid == '20250101101010'")
+	options.DatabaseURL = string(connectionURL)
+
+	report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+	require.NoError(t, migrator.ApplyPending(t.Context(), options, &report))
+
+	assert.Equal(t, 1, report.PendingCount)
+	assert.Equal(t, []source.ID{source.NewIDFromString("20250102101010")}, report.SkippedIDs)
+
+	tests.AssertPgTableExist(t, conn, "soaked")
+	tests.AssertPgTableNotExist(t, conn, "fresh")
+}
+
+func TestApplyPendingFilterCompileError(t *testing.T) {
+	t.Parallel()
+
+	options := newFilterApplyOptions(t.TempDir(), "ageDays >=")
+	report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+
+	err := migrator.ApplyPending(t.Context(), options, &report)
+
+	var migrateErr *migrator.MigrateError
+
+	var compileErr *source.CompileFilterError
+
+	require.ErrorAs(t, err, &migrateErr)
+	assert.Equal(t, migrator.ErrTypeFilterMigrations, migrateErr.ErrType)
+	require.ErrorAs(t, err, &compileErr)
+	assert.Equal(t, "ageDays >=", compileErr.Expression)
+}
+
+func newFilterApplyOptions(dir string, filter string) migrator.ApplyOptions {
+	options := newApplyOptions("", dir)
+	options.Filter = filter
+
+	return options
+}
@@ -0,0 +1,294 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/servletcloud/Andmerada/internal/buildinfo"
+	"github.com/servletcloud/Andmerada/internal/migrator/sqlres"
+	"github.com/servletcloud/Andmerada/internal/project"
+	"github.com/servletcloud/Andmerada/internal/source"
+)
+
+type HistoryOperation string
+
+const (
+	OperationApply    HistoryOperation = "apply"
+	OperationRollback HistoryOperation = "rollback"
+)
+
+type HistoryOutcome string
+
+const (
+	OutcomeSucceeded HistoryOutcome = "succeeded"
+	OutcomeFailed    HistoryOutcome = "failed"
+)
+
+func (outcome HistoryOutcome) IsValid() bool {
+	return outcome == OutcomeSucceeded || outcome == OutcomeFailed
+}
+
+// Attempt is one execution of the up or down SQL of a migration, including the failed ones.
+// DatabaseUser is filled in by the database from current_user.
+type Attempt struct {
+	ID               int64            `json:"attempt_id"`
+	MigrationID      source.ID        `json:"migration_id"`
+	Name             string           `json:"name"`
+	Operation        HistoryOperation `json:"operation"`
+	StartedAt        time.Time        `json:"started_at"`
+	FinishedAt       time.Time        `json:"finished_at"`
+	Outcome          HistoryOutcome   `json:"outcome"`
+	SQLState         string           `json:"sql_state"`
+	Message          string           `json:"message"`
+	AndmeradaVersion string           `json:"andmerada_version"`
+	DatabaseUser     string           `json:"database_user"`
+	ClientHostname   string           `json:"client_hostname"`
+	GitCommit        string           `json:"git_commit"`
+}
+
+// HistoryFilter narrows down the listed attempts. Zero values match everything,
+// and Limit keeps only the latest attempts.
+type HistoryFilter struct {
+	MigrationID source.ID
+	Outcome     HistoryOutcome
+	Limit       int
+}
+
+type HistoryOptions struct {
+	DatabaseURL string
+	Project     project.Project
+	Filter      HistoryFilter
+}
+
+// History is the audit trail of every attempt, stored next to the migrations table in TableName_history.
+type History struct {
+	TableName string
+}
+
+func ListHistory(ctx context.Context, options HistoryOptions) ([]Attempt, error) {
+	connection, err := connect(ctx, options.DatabaseURL, options.Project.Configuration.SearchPath)
+
+	defer closeConnection(ctx, connection) //nolint:errcheck
+
+	if err != nil {
+		return nil, wrapError(err, ErrTypeDBConnect)
+	}
+
+	configuration := options.Project.Configuration
+	migrationsRepo := &Migrations{TableName: configuration.MigrationsTableName, CreateSchema: configuration.CreateSchema}
+
+	if _, err := checkSchemaVersion(ctx, connection, migrationsRepo); err != nil {
+		return nil, err
+	}
+
+	history := &History{TableName: configuration.MigrationsTableName}
+
+	attempts, err := history.List(ctx, connection, options.Filter)
+	if err != nil && !isUndefinedTableError(err) {
+		return nil, wrapError(err, ErrTypeListHistory)
+	}
+
+	return attempts, nil
+}
+
+func (h *History) historyTableName() string {
+	return sqlres.HistoryTable(h.TableName).Sanitize()
+}
+
+func (h *History) Insert(ctx context.Context, conn *pgx.Conn, attempt *Attempt) error {
+	queryTemplate := `
+		INSERT INTO %s (
+			migration_id, name, operation, started_at, finished_at, outcome, sqlstate,
+			message, andmerada_version, client_hostname, git_commit
+		) VALUES (
+			@migration_id, @name, @operation, @started_at, @finished_at, @outcome, NULLIF(@sqlstate, ''),
+			NULLIF(@message, ''), @andmerada_version, NULLIF(@client_hostname, ''), NULLIF(@git_commit, '')
+		)`
+	query := fmt.Sprintf(queryTemplate, h.historyTableName())
+
+	args := pgx.NamedArgs{
+		"migration_id":      attempt.MigrationID,
+		"name":              attempt.Name,
+		"operation":         string(attempt.Operation),
+		"started_at":        attempt.StartedAt,
+		"finished_at":       attempt.FinishedAt,
+		"outcome":           string(attempt.Outcome),
+		"sqlstate":          attempt.SQLState,
+		"message":           attempt.Message,
+		"andmerada_version": attempt.AndmeradaVersion,
+		"client_hostname":   attempt.ClientHostname,
+		"git_commit":        attempt.GitCommit,
+	}
+
+	if _, err := conn.Exec(ctx, query, args); err != nil {
+		return &ExecSQLError{Cause: err, SQL: query}
+	}
+
+	return nil
+}
+
+func (h *History) List(ctx context.Context, conn *pgx.Conn, filter HistoryFilter) ([]Attempt, error) {
+	queryTemplate := `
+		SELECT * FROM (
+			SELECT attempt_id, migration_id, name, operation, started_at, finished_at, outcome,
+			       COALESCE(sqlstate, '') AS sqlstate, COALESCE(message, '') AS message,
+			       andmerada_version, db_user, COALESCE(client_hostname, '') AS client_hostname,
+			       COALESCE(git_commit, '') AS git_commit
+			FROM %s
+			WHERE (@migration_id = 0 OR migration_id = @migration_id) AND (@outcome = '' OR outcome = @outcome)
+			ORDER BY attempt_id DESC
+			LIMIT NULLIF(@limit, 0)
+		) AS latest ORDER BY attempt_id`
+	query := fmt.Sprintf(queryTemplate, h.historyTableName())
+
+	args := pgx.NamedArgs{
+		"migration_id": filter.MigrationID,
+		"outcome":      string(filter.Outcome),
+		"limit":        filter.Limit,
+	}
+
+	rows, err := conn.Query(ctx, query, args)
+	if err != nil {
+		return nil, &ExecSQLError{Cause: err, SQL: query}
+	}
+
+	attempts, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (Attempt, error) {
+		var attempt Attempt
+
+		err := row.Scan(
+			&attempt.ID,
+			&attempt.MigrationID,
+			&attempt.Name,
+			&attempt.Operation,
+			&attempt.StartedAt,
+			&attempt.FinishedAt,
+			&attempt.Outcome,
+			&attempt.SQLState,
+			&attempt.Message,
+			&attempt.AndmeradaVersion,
+			&attempt.DatabaseUser,
+			&attempt.ClientHostname,
+			&attempt.GitCommit,
+		)
+
+		return attempt, err //nolint:wrapcheck
+	})
+
+	if err != nil {
+		return nil, &ExecSQLError{Cause: err, SQL: query}
+	}
+
+	return attempts, nil
+}
+
+// historyRecorder writes attempts to the history table on a best-effort basis:
+// a failure to record is logged and never fails the migration itself.
+type historyRecorder struct {
+	repo           *History
+	version        string
+	clientHostname string
+	gitCommit      string
+	disabled       bool
+}
+
+func newHistoryRecorder(tableName string, projectDir string) *historyRecorder {
+	return &historyRecorder{
+		repo:           &History{TableName: tableName},
+		version:        buildinfo.Version,
+		clientHostname: buildinfo.Hostname(),
+		gitCommit:      buildinfo.GitCommit(projectDir),
+		disabled:       false,
+	}
+}
+
+func (recorder *historyRecorder) record(
+	ctx context.Context,
+	conn *pgx.Conn,
+	operation HistoryOperation,
+	id source.ID,
+	name string,
+	startedAt time.Time,
+	cause error,
+) {
+	if recorder.disabled {
+		return
+	}
+
+	if conn.IsClosed() {
+		log.Printf("The %s attempt of %v is not recorded in the history: the connection is closed", operation, id)
+
+		return
+	}
+
+	// A failed migration may leave an aborted transaction behind, which rejects any further statement.
+	if isConnectionInTransaction(conn.PgConn()) {
+		if err := execSimple(ctx, conn.PgConn(), "ROLLBACK;"); err != nil {
+			log.Printf("The %s attempt of %v is not recorded in the history: %v", operation, id, err)
+
+			return
+		}
+	}
+
+	attempt := recorder.newAttempt(operation, id, name, startedAt, cause)
+
+	err := recorder.repo.Insert(ctx, conn, attempt)
+
+	switch {
+	case err == nil:
+	case isUndefinedTableError(err):
+		recorder.disabled = true
+
+		log.Printf(
+			"Warning: the execution history is not recorded because the table %s does not exist. "+
+				"Run 'andmerada show-ddl' to see its definition.",
+			recorder.repo.historyTableName(),
+		)
+	default:
+		log.Printf("The %s attempt of %v is not recorded in the history: %v", operation, id, err)
+	}
+}
+
+func (recorder *historyRecorder) newAttempt(
+	operation HistoryOperation,
+	id source.ID,
+	name string,
+	startedAt time.Time,
+	cause error,
+) *Attempt {
+	attempt := &Attempt{
+		ID:               0,
+		MigrationID:      id,
+		Name:             name,
+		Operation:        operation,
+		StartedAt:        startedAt.UTC(),
+		FinishedAt:       time.Now().UTC(),
+		Outcome:          OutcomeSucceeded,
+		SQLState:         "",
+		Message:          "",
+		AndmeradaVersion: recorder.version,
+		DatabaseUser:     "",
+		ClientHostname:   recorder.clientHostname,
+		GitCommit:        recorder.gitCommit,
+	}
+
+	if cause == nil {
+		return attempt
+	}
+
+	attempt.Outcome = OutcomeFailed
+	attempt.Message = cause.Error()
+
+	var pgError *pgconn.PgError
+
+	if errors.As(cause, &pgError) {
+		attempt.SQLState = pgError.Code
+		attempt.Message = pgError.Message
+	}
+
+	return attempt
+}
@@ -0,0 +1,115 @@
+package migrator_test
+
+import (
+	"testing"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/servletcloud/Andmerada/internal/buildinfo"
+	"github.com/servletcloud/Andmerada/internal/migrator"
+	"github.com/servletcloud/Andmerada/internal/project"
+	"github.com/servletcloud/Andmerada/internal/source"
+	"github.com/servletcloud/Andmerada/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:paralleltest,funlen
+func TestHistory(t *testing.T) {
+	connectionURL := tests.StartEmbeddedPostgres(t)
+	conn := tests.OpenPgConnection(t, connectionURL)
+	dir := t.TempDir()
+	testProject := project.Project{Dir: dir, Configuration: createProjectConfig(), Callbacks: nil}
+
+	applyOptions := newApplyOptions(connectionURL, dir)
+
+	listHistory := func(t *testing.T, filter migrator.HistoryFilter) []migrator.Attempt {
+		t.Helper()
+
+		options := migrator.HistoryOptions{DatabaseURL: string(connectionURL), Project: testProject, Filter: filter}
+
+		attempts, err := migrator.ListHistory(t.Context(), options)
+		require.NoError(t, err)
+
+		return attempts
+	}
+
+	succeededID, failedID := source.ID(20250801101010), source.ID(20250802101010)
+
+	succeeded := tests.CreateSource(t, dir, "Create users", succeededID.String())
+	writeUpSQL(t, succeeded.FullPath, "CREATE TABLE history_users (id INTEGER);")
+	writeDownSQL(t, succeeded.FullPath, "DROP TABLE history_users;")
+
+	failed := tests.CreateSource(t, dir, "Broken", failedID.String())
+	writeUpSQL(t, failed.FullPath, "BEGIN;\nINSERT INTO missing VALUES (1);\nCOMMIT;")
+
+	report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+	require.Error(t, migrator.ApplyPending(t.Context(), applyOptions, &report))
+
+	t.Run("Records successful and failed attempts", func(t *testing.T) {
+		attempts := listHistory(t, migrator.HistoryFilter{MigrationID: 0, Outcome: "", Limit: 0})
+
+		require.Len(t, attempts, 2)
+
+		first, second := attempts[0], attempts[1]
+
+		assert.Equal(t, succeededID, first.MigrationID)
+		assert.Equal(t, "Create users", first.Name)
+		assert.Equal(t, migrator.OperationApply, first.Operation)
+		assert.Equal(t, migrator.OutcomeSucceeded, first.Outcome)
+		assert.Empty(t, first.SQLState)
+		assert.Equal(t, buildinfo.Version, first.AndmeradaVersion)
+		assert.Equal(t, "postgres", first.DatabaseUser)
+		assert.False(t, first.FinishedAt.Before(first.StartedAt))
+
+		assert.Equal(t, failedID, second.MigrationID)
+		assert.Equal(t, migrator.OutcomeFailed, second.Outcome)
+		assert.Equal(t, pgerrcode.UndefinedTable, second.SQLState)
+		assert.Contains(t, second.Message, "missing")
+	})
+
+	t.Run("Filters by migration ID and outcome", func(t *testing.T) {
+		byID := listHistory(t, migrator.HistoryFilter{MigrationID: succeededID, Outcome: "", Limit: 0})
+		require.Len(t, byID, 1)
+		assert.Equal(t, succeededID, byID[0].MigrationID)
+
+		byOutcome := listHistory(t, migrator.HistoryFilter{MigrationID: 0, Outcome: migrator.OutcomeFailed, Limit: 0})
+		require.Len(t, byOutcome, 1)
+		assert.Equal(t, failedID, byOutcome[0].MigrationID)
+
+		latest := listHistory(t, migrator.HistoryFilter{MigrationID: 0, Outcome: "", Limit: 1})
+		require.Len(t, latest, 1)
+		assert.Equal(t, failedID, latest[0].MigrationID)
+	})
+
+	t.Run("Records rollbacks", func(t *testing.T) {
+		rollbackOptions := migrator.RollbackOptions{
+			MaxSQLFileSize: 1024,
+			DatabaseURL:    string(connectionURL),
+			Project:        testProject,
+			Count:          1,
+			ToID:           source.EmptyMigrationID,
+			DownSQLSource:  migrator.DownSQLFromDatabase,
+			DryRun:         false,
+			LockTimeout:    migrator.DefaultLockTimeout,
+			Placeholders:   nil,
+		}
+
+		require.NoError(t, migrator.Rollback(t.Context(), rollbackOptions, &migrator.RollbackReport{TargetCount: 0}))
+
+		attempts := listHistory(t, migrator.HistoryFilter{MigrationID: succeededID, Outcome: "", Limit: 0})
+
+		require.Len(t, attempts, 2)
+		assert.Equal(t, migrator.OperationRollback, attempts[1].Operation)
+		assert.Equal(t, migrator.OutcomeSucceeded, attempts[1].Outcome)
+	})
+
+	t.Run("A missing history table does not fail migrations", func(t *testing.T) {
+		_, err := conn.Exec(t.Context(), "DROP TABLE migrations_history;")
+		require.NoError(t, err)
+
+		writeUpSQL(t, failed.FullPath, "CREATE TABLE history_fixed (id INTEGER);")
+
+		require.NoError(t, migrator.ApplyPending(t.Context(), applyOptions, &report))
+		assert.Empty(t, listHistory(t, migrator.HistoryFilter{MigrationID: 0, Outcome: "", Limit: 0}))
+	})
+}
@@ -0,0 +1,59 @@
+package migrator
+
+import (
+	"context"
+	"log"
+
+	"github.com/servletcloud/Andmerada/internal/settings"
+)
+
+// isolateSession cleans up the session state that the last migration left on the connection,
+// so that e.g. its plain SET or SET ROLE does not leak into the next migration.
+func (applier *applier) isolateSession(ctx context.Context) error {
+	if applier.dryRun {
+		return nil
+	}
+
+	switch applier.isolation {
+	case settings.IsolationReset:
+		return applier.resetSession(ctx, "RESET ALL;")
+	case settings.IsolationDiscard:
+		if err := applier.connection.DeallocateAll(ctx); err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		return applier.resetSession(ctx, "DISCARD ALL;")
+	case settings.IsolationReconnect:
+		return applier.reconnect(ctx)
+	case settings.IsolationNone:
+		return nil
+	default:
+		return nil
+	}
+}
+
+// resetSession runs the SQL and sets the search_path of andmerada.yml again, which the SQL resets.
+func (applier *applier) resetSession(ctx context.Context, sql string) error {
+	if err := execSimple(ctx, applier.connection.PgConn(), sql); err != nil {
+		return &ExecSQLError{Cause: err, SQL: sql}
+	}
+
+	return setSearchPath(ctx, applier.connection, applier.searchPath)
+}
+
+// reconnect replaces the connection of the migrations with a fresh one.
+// The migrations lock stays held by the lock connection meanwhile.
+func (applier *applier) reconnect(ctx context.Context) error {
+	connection, err := connectWithNotices(ctx, applier.databaseURL, applier.searchPath, applier.handleNotice)
+	if err != nil {
+		return err
+	}
+
+	if err := closeConnection(ctx, applier.connection); err != nil {
+		log.Println("Warning: the previous connection was not closed gracefully:", err)
+	}
+
+	applier.connection = connection
+
+	return nil
+}
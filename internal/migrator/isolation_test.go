@@ -0,0 +1,120 @@
+package migrator_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/servletcloud/Andmerada/internal/migrator"
+	"github.com/servletcloud/Andmerada/internal/settings"
+	"github.com/servletcloud/Andmerada/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:paralleltest,funlen
+func TestIsolation(t *testing.T) {
+	connectionURL := tests.StartEmbeddedPostgres(t)
+	conn := tests.OpenPgConnection(t, connectionURL)
+
+	newOptions := func(dir string, isolation settings.IsolationMode) migrator.ApplyOptions {
+		options := newApplyOptions(connectionURL, dir)
+		options.Project.Configuration.Isolation = isolation
+		options.AllowDrift = true
+
+		return options
+	}
+
+	_, err := conn.Exec(t.Context(), "CREATE SCHEMA app; CREATE TABLE public.isolation_log (seq SERIAL, state TEXT);")
+	require.NoError(t, err)
+
+	leakState := "SET search_path TO app;\nCREATE TEMP TABLE leaked (id INTEGER);"
+	logState := "INSERT INTO public.isolation_log (state) VALUES (concat_ws(' ', " +
+		"current_setting('search_path'), to_regclass('pg_temp.leaked') IS NOT NULL));"
+
+	apply := func(t *testing.T, isolation settings.IsolationMode, firstID string, secondID string) (string, error) {
+		t.Helper()
+
+		dir := t.TempDir()
+
+		first := tests.CreateSource(t, dir, "Leak", firstID)
+		writeUpSQL(t, first.FullPath, leakState)
+
+		second := tests.CreateSource(t, dir, "Log", secondID)
+		writeUpSQL(t, second.FullPath, logState)
+
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+		err := migrator.ApplyPending(t.Context(), newOptions(dir, isolation), &report)
+
+		var state string
+
+		_ = conn.QueryRow(t.Context(), "DELETE FROM public.isolation_log RETURNING state").Scan(&state)
+
+		return state, err
+	}
+
+	t.Run("Without isolation, the session state leaks into the bookkeeping", func(t *testing.T) {
+		_, err := apply(t, settings.IsolationNone, "20250701101010", "20250701111010")
+
+		var migrateErr *migrator.MigrateError
+
+		require.ErrorAs(t, err, &migrateErr)
+		assert.Equal(t, migrator.ErrTypeRegisterMigration, migrateErr.ErrType)
+	})
+
+	t.Run("reset resets the settings but keeps temporary tables", func(t *testing.T) {
+		state, err := apply(t, settings.IsolationReset, "20250702101010", "20250702111010")
+		require.NoError(t, err)
+
+		assert.Equal(t, `"$user", public true`, state)
+	})
+
+	t.Run("discard resets the settings and drops temporary tables", func(t *testing.T) {
+		state, err := apply(t, settings.IsolationDiscard, "20250703101010", "20250703111010")
+		require.NoError(t, err)
+
+		assert.Equal(t, `"$user", public false`, state)
+	})
+
+	t.Run("reconnect starts every migration on a fresh connection", func(t *testing.T) {
+		state, err := apply(t, settings.IsolationReconnect, "20250704101010", "20250704111010")
+		require.NoError(t, err)
+
+		assert.Equal(t, `"$user", public false`, state)
+
+		rows, err := conn.Query(t.Context(), "SELECT id FROM migrations WHERE id >= 20250704000000 ORDER BY id")
+		require.NoError(t, err)
+
+		ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+		require.NoError(t, err)
+
+		assert.Equal(t, []int64{20250704101010, 20250704111010}, ids)
+	})
+
+	t.Run("reconnect keeps the migrations lock, so no other process can take it in between", func(t *testing.T) {
+		dir := t.TempDir()
+		lockKey, err := (&migrator.Lock{TableName: "migrations"}).Key(t.Context(), conn)
+		require.NoError(t, err)
+
+		key := strconv.FormatInt(lockKey, 10)
+
+		// Each migration runs on a fresh connection and tries to take the lock like another andmerada process would.
+		probe := "INSERT INTO public.isolation_log (state) SELECT pg_try_advisory_lock(" + key + ")::TEXT;"
+
+		for _, id := range []string{"20250705101010", "20250705111010", "20250705121010"} {
+			result := tests.CreateSource(t, dir, "Probe", id)
+			writeUpSQL(t, result.FullPath, probe)
+		}
+
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+		require.NoError(t, migrator.ApplyPending(t.Context(), newOptions(dir, settings.IsolationReconnect), &report))
+
+		rows, err := conn.Query(t.Context(), "DELETE FROM public.isolation_log RETURNING state")
+		require.NoError(t, err)
+
+		states, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"false", "false", "false"}, states)
+	})
+}
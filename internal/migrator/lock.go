@@ -0,0 +1,226 @@
+package migrator
+
+import (
+	"context"
+	"hash/fnv"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/servletcloud/Andmerada/internal/migrator/sqlres"
+	"github.com/servletcloud/Andmerada/internal/project"
+)
+
+const (
+	DefaultLockTimeout = 5 * time.Minute
+
+	lockPollInterval = 500 * time.Millisecond
+	lockKeyPrefix    = "andmerada:"
+)
+
+type LockHolder struct {
+	PID             uint32
+	ApplicationName string
+	UserName        string
+	ClientAddr      string
+	BackendStart    time.Time
+}
+
+type LockOptions struct {
+	DatabaseURL string
+	Project     project.Project
+}
+
+type Lock struct {
+	TableName string
+}
+
+func LockStatus(ctx context.Context, options LockOptions) ([]LockHolder, error) {
+	return withConnection(ctx, options, func(lock *Lock, conn *pgx.Conn) ([]LockHolder, error) {
+		return lock.Holders(ctx, conn)
+	})
+}
+
+func ReleaseLock(ctx context.Context, options LockOptions) ([]LockHolder, error) {
+	return withConnection(ctx, options, func(lock *Lock, conn *pgx.Conn) ([]LockHolder, error) {
+		return lock.TerminateHolders(ctx, conn)
+	})
+}
+
+// withConnection opens a connection to inspect or release the lock of the project. It does not take the lock itself.
+func withConnection(
+	ctx context.Context,
+	options LockOptions,
+	callback func(lock *Lock, conn *pgx.Conn) ([]LockHolder, error),
+) ([]LockHolder, error) {
+	connection, err := connect(ctx, options.DatabaseURL, options.Project.Configuration.SearchPath)
+
+	defer closeConnection(ctx, connection) //nolint:errcheck
+
+	if err != nil {
+		return nil, wrapError(err, ErrTypeDBConnect)
+	}
+
+	lock := &Lock{TableName: options.Project.Configuration.MigrationsTableName}
+
+	holders, err := callback(lock, connection)
+	if err != nil {
+		return nil, wrapError(err, ErrTypeQueryLock)
+	}
+
+	return holders, nil
+}
+
+// Key returns the advisory lock key of the migrations table. It hashes the schema-qualified identifier,
+// so that e.g. "migrations" and "public.migrations" take the same lock. An unqualified table is resolved
+// to current_schema(), where it is or will be created.
+func (l *Lock) Key(ctx context.Context, conn *pgx.Conn) (int64, error) {
+	table := sqlres.ParseTable(l.TableName)
+
+	if table.Schema == "" {
+		query := "SELECT COALESCE(current_schema(), '')"
+
+		if err := conn.QueryRow(ctx, query).Scan(&table.Schema); err != nil {
+			return 0, &ExecSQLError{Cause: err, SQL: query}
+		}
+	}
+
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(lockKeyPrefix + table.Sanitize()))
+
+	return int64(hash.Sum64()), nil //nolint:gosec
+}
+
+func (l *Lock) TryAcquire(ctx context.Context, conn *pgx.Conn) (bool, error) {
+	key, err := l.Key(ctx, conn)
+	if err != nil {
+		return false, err
+	}
+
+	query := "SELECT pg_try_advisory_lock($1)"
+
+	var acquired bool
+
+	if err := conn.QueryRow(ctx, query, key).Scan(&acquired); err != nil {
+		return false, &ExecSQLError{Cause: err, SQL: query}
+	}
+
+	return acquired, nil
+}
+
+func (l *Lock) Acquire(ctx context.Context, conn *pgx.Conn, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	logged := false
+
+	for {
+		acquired, err := l.TryAcquire(ctx, conn)
+		if err != nil || acquired {
+			return err
+		}
+
+		if !time.Now().Before(deadline) {
+			return l.newTimeoutError(ctx, conn, timeout)
+		}
+
+		if !logged {
+			log.Printf("Waiting up to %v for another andmerada process to release the migrations lock...", timeout)
+
+			logged = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err() //nolint:wrapcheck
+		case <-time.After(min(lockPollInterval, time.Until(deadline))):
+		}
+	}
+}
+
+func (l *Lock) Release(ctx context.Context, conn *pgx.Conn) error {
+	key, err := l.Key(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	query := "SELECT pg_advisory_unlock($1)"
+
+	if _, err := conn.Exec(ctx, query, key); err != nil {
+		return &ExecSQLError{Cause: err, SQL: query}
+	}
+
+	return nil
+}
+
+func (l *Lock) Holders(ctx context.Context, conn *pgx.Conn) ([]LockHolder, error) {
+	query := `
+		SELECT a.pid, COALESCE(a.application_name, ''), COALESCE(a.usename, ''),
+		       COALESCE(host(a.client_addr), ''), a.backend_start
+		FROM pg_locks l
+		JOIN pg_stat_activity a ON a.pid = l.pid
+		WHERE l.locktype = 'advisory'
+		  AND l.granted
+		  AND l.database = (SELECT oid FROM pg_database WHERE datname = current_database())
+		  AND l.classid::bigint = $1
+		  AND l.objid::bigint = $2
+		  AND l.objsubid = 1
+		ORDER BY a.pid`
+
+	signedKey, err := l.Key(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	key := uint64(signedKey)             //nolint:gosec
+	high, low := key>>32, key&0xFFFFFFFF //nolint:mnd
+
+	rows, err := conn.Query(ctx, query, int64(high), int64(low)) //nolint:gosec
+	if err != nil {
+		return nil, &ExecSQLError{Cause: err, SQL: query}
+	}
+
+	holders, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (LockHolder, error) {
+		var holder LockHolder
+
+		err := row.Scan(
+			&holder.PID,
+			&holder.ApplicationName,
+			&holder.UserName,
+			&holder.ClientAddr,
+			&holder.BackendStart,
+		)
+
+		return holder, err //nolint:wrapcheck
+	})
+
+	if err != nil {
+		return nil, &ExecSQLError{Cause: err, SQL: query}
+	}
+
+	return holders, nil
+}
+
+func (l *Lock) TerminateHolders(ctx context.Context, conn *pgx.Conn) ([]LockHolder, error) {
+	holders, err := l.Holders(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	query := "SELECT pg_terminate_backend($1)"
+
+	for _, holder := range holders {
+		if _, err := conn.Exec(ctx, query, holder.PID); err != nil {
+			return nil, &ExecSQLError{Cause: err, SQL: query}
+		}
+	}
+
+	return holders, nil
+}
+
+func (l *Lock) newTimeoutError(ctx context.Context, conn *pgx.Conn, timeout time.Duration) error {
+	holders, err := l.Holders(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	return &LockTimeoutError{Timeout: timeout, Holders: holders}
+}
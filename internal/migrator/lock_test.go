@@ -0,0 +1,127 @@
+package migrator_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/servletcloud/Andmerada/internal/migrator"
+	"github.com/servletcloud/Andmerada/internal/project"
+	"github.com/servletcloud/Andmerada/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:paralleltest,funlen
+func TestLock(t *testing.T) {
+	connectionURL := tests.StartEmbeddedPostgres(t)
+	holderConn := tests.OpenPgConnection(t, connectionURL)
+	waiterConn := tests.OpenPgConnection(t, connectionURL)
+
+	lock := &migrator.Lock{TableName: "migrations"}
+
+	key := func(t *testing.T, tableName string) int64 {
+		t.Helper()
+
+		key, err := (&migrator.Lock{TableName: tableName}).Key(t.Context(), holderConn)
+		require.NoError(t, err)
+
+		return key
+	}
+
+	t.Run("Different tables use different keys", func(t *testing.T) {
+		assert.NotEqual(t, key(t, "migrations"), key(t, "other_migrations"))
+	})
+
+	t.Run("The same table uses the same key with or without its schema", func(t *testing.T) {
+		assert.Equal(t, key(t, "migrations"), key(t, "public.migrations"))
+	})
+
+	t.Run("Acquire succeeds when the lock is free", func(t *testing.T) {
+		require.NoError(t, lock.Acquire(t.Context(), holderConn, 0))
+		require.NoError(t, lock.Release(t.Context(), holderConn))
+	})
+
+	t.Run("Acquire times out and names the holder", func(t *testing.T) {
+		require.NoError(t, lock.Acquire(t.Context(), holderConn, 0))
+
+		t.Cleanup(func() {
+			require.NoError(t, lock.Release(t.Context(), holderConn))
+		})
+
+		err := lock.Acquire(t.Context(), waiterConn, time.Second)
+
+		var timeoutErr *migrator.LockTimeoutError
+
+		require.ErrorAs(t, err, &timeoutErr)
+		require.Len(t, timeoutErr.Holders, 1)
+		assert.Equal(t, holderConn.PgConn().PID(), timeoutErr.Holders[0].PID)
+		assert.Contains(t, timeoutErr.Error(), "held by PID")
+	})
+
+	t.Run("ApplyPending fails while another process holds the lock", func(t *testing.T) {
+		dir := t.TempDir()
+		result := tests.CreateSource(t, dir, "Locked", "20250201101010")
+		writeUpSQL(t, result.FullPath, "CREATE TABLE locked (id INTEGER);")
+
+		require.NoError(t, lock.Acquire(t.Context(), holderConn, 0))
+
+		t.Cleanup(func() {
+			require.NoError(t, lock.Release(t.Context(), holderConn))
+		})
+
+		options := newApplyOptions(connectionURL, dir)
+		options.LockTimeout = 0
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+
+		err := migrator.ApplyPending(t.Context(), options, &report)
+
+		var applierErr *migrator.MigrateError
+
+		require.ErrorAs(t, err, &applierErr)
+		assert.Equal(t, migrator.ErrTypeAcquireLock, applierErr.ErrType)
+		tests.AssertPgTableNotExist(t, waiterConn, "locked")
+	})
+
+	t.Run("The lock holder of ApplyPending is named in pg_stat_activity", func(t *testing.T) {
+		dir := t.TempDir()
+		result := tests.CreateSource(t, dir, "Holder", "20250202101010")
+		writeUpSQL(t, result.FullPath, "DO $$ BEGIN IF NOT EXISTS ("+
+			"SELECT 1 FROM pg_locks l JOIN pg_stat_activity a ON a.pid = l.pid "+
+			"WHERE l.locktype = 'advisory' AND l.granted AND a.application_name = 'andmerada:migrate'"+
+			") THEN RAISE EXCEPTION 'the lock holder has no application_name'; END IF; END $$;")
+
+		options := newApplyOptions(connectionURL, dir)
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+
+		require.NoError(t, migrator.ApplyPending(t.Context(), options, &report))
+	})
+
+	t.Run("Lock status and release", func(t *testing.T) {
+		otherConn, err := pgx.Connect(t.Context(), string(connectionURL))
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			_ = otherConn.Close(t.Context())
+		})
+
+		require.NoError(t, lock.Acquire(t.Context(), otherConn, 0))
+
+		options := migrator.LockOptions{
+			DatabaseURL: string(connectionURL),
+			Project:     project.Project{Dir: t.TempDir(), Configuration: createProjectConfig(), Callbacks: nil},
+		}
+
+		holders, err := migrator.LockStatus(t.Context(), options)
+		require.NoError(t, err)
+		require.Len(t, holders, 1)
+		assert.Equal(t, otherConn.PgConn().PID(), holders[0].PID)
+
+		released, err := migrator.ReleaseLock(t.Context(), options)
+		require.NoError(t, err)
+		assert.Len(t, released, 1)
+
+		require.NoError(t, lock.Acquire(t.Context(), waiterConn, time.Second))
+		require.NoError(t, lock.Release(t.Context(), waiterConn))
+	})
+}
@@ -23,27 +23,87 @@ type Migration struct {
 	Meta            map[string]any
 }
 
+func NewMigration(id source.ID, source *source.Source, duration time.Duration) *Migration {
+	return &Migration{
+		ID:              id,
+		Name:            source.Configuration.Name,
+		AppliedAt:       time.Now().UTC(),
+		SQLUp:           source.UpSQL,
+		SQLDown:         source.DownSQL,
+		SQLUpSHA256:     Sha256ToHexStr(source.UpSQL),
+		SQLDownSHA256:   Sha256ToHexStr(source.DownSQL),
+		DurationMs:      duration.Milliseconds(),
+		RollbackBlocked: source.Configuration.Down.Block,
+		Meta:            source.Configuration.Meta,
+	}
+}
+
+// Migrations is the repository of applied migrations. TableName may be qualified with a schema,
+// which is created together with the tables when CreateSchema is set.
 type Migrations struct {
-	TableName string
+	TableName    string
+	CreateSchema bool
 }
 
 func (m *Migrations) RunDDL(ctx context.Context, conn *pgx.Conn) error {
-	ddl := sqlres.DDL(m.TableName)
+	return m.Upgrade(ctx, conn, 0)
+}
+
+// Upgrade brings the bookkeeping schema from fromVersion to the latest version. The steps run
+// as one implicit transaction, so a failed upgrade leaves the schema at fromVersion.
+func (m *Migrations) Upgrade(ctx context.Context, conn *pgx.Conn, fromVersion int) error {
+	sql := sqlres.UpgradeSQL(m.TableName, fromVersion)
+
+	if m.CreateSchema {
+		sql = sqlres.SchemaDDL(m.TableName) + sql
+	}
 
-	if err := execSimple(ctx, conn.PgConn(), ddl); err != nil {
-		return &ExecSQLError{Cause: err, SQL: ddl}
+	if err := execSimple(ctx, conn.PgConn(), sql); err != nil {
+		return &ExecSQLError{Cause: err, SQL: sql}
 	}
 
 	return nil
 }
 
+// SchemaVersion returns the version of the bookkeeping schema: 0 when it does not exist,
+// and legacySchemaVersion for installations that predate the schema version table.
+func (m *Migrations) SchemaVersion(ctx context.Context, conn *pgx.Conn) (int, error) {
+	versionTable := sqlres.SchemaVersionTable(m.TableName).Sanitize()
+	query := fmt.Sprintf("SELECT COALESCE(MAX(version), 0) FROM %s", versionTable)
+
+	var version int
+
+	err := conn.QueryRow(ctx, query).Scan(&version)
+	if err == nil {
+		return version, nil
+	}
+
+	if !isUndefinedTableError(err) {
+		return 0, &ExecSQLError{Cause: err, SQL: query}
+	}
+
+	query = "SELECT to_regclass($1) IS NOT NULL"
+
+	var exists bool
+
+	if err := conn.QueryRow(ctx, query, m.sanitizedTableName()).Scan(&exists); err != nil {
+		return 0, &ExecSQLError{Cause: err, SQL: query}
+	}
+
+	if exists {
+		return legacySchemaVersion, nil
+	}
+
+	return 0, nil
+}
+
 func (m *Migrations) ScanApplied(
 	ctx context.Context,
 	conn *pgx.Conn,
 	minID, maxID source.ID,
 ) ([]source.ID, error) {
 	queryTemplate := "SELECT id FROM %s WHERE id >= $1 AND id <= $2"
-	query := fmt.Sprintf(queryTemplate, m.TableName)
+	query := fmt.Sprintf(queryTemplate, m.sanitizedTableName())
 
 	rows, err := conn.Query(ctx, query, minID, maxID)
 
@@ -60,8 +120,60 @@ func (m *Migrations) ScanApplied(
 	return ids, nil
 }
 
+// ListApplied returns the applied versioned migrations ordered by ID.
+func (m *Migrations) ListApplied(ctx context.Context, conn *pgx.Conn) ([]Migration, error) {
+	return m.list(ctx, conn, "id <= $1", source.MaxMigrationID)
+}
+
+// ListRepeatables returns the last applied version of each repeatable migration.
+func (m *Migrations) ListRepeatables(ctx context.Context, conn *pgx.Conn) ([]Migration, error) {
+	return m.list(ctx, conn, "id >= $1", source.MinRepeatableID)
+}
+
+func (m *Migrations) list(ctx context.Context, conn *pgx.Conn, condition string, arg source.ID) ([]Migration, error) {
+	queryTemplate := `
+		SELECT id, name, applied_at, sql_up, COALESCE(sql_down, ''), sql_up_sha256,
+		       COALESCE(sql_down_sha256, ''), duration_ms, rollback_blocked, meta
+		FROM %s WHERE %s ORDER BY id`
+	query := fmt.Sprintf(queryTemplate, m.sanitizedTableName(), condition)
+
+	rows, err := conn.Query(ctx, query, arg)
+
+	if err != nil {
+		return nil, &ExecSQLError{Cause: err, SQL: query}
+	}
+
+	migrations, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (Migration, error) {
+		var migration Migration
+
+		err := row.Scan(
+			&migration.ID,
+			&migration.Name,
+			&migration.AppliedAt,
+			&migration.SQLUp,
+			&migration.SQLDown,
+			&migration.SQLUpSHA256,
+			&migration.SQLDownSHA256,
+			&migration.DurationMs,
+			&migration.RollbackBlocked,
+			&migration.Meta,
+		)
+
+		return migration, err //nolint:wrapcheck
+	})
+
+	if err != nil {
+		return nil, &ExecSQLError{Cause: err, SQL: query}
+	}
+
+	return migrations, nil
+}
+
 func (m *Migrations) Insert(ctx context.Context, conn *pgx.Conn, migration *Migration) error {
 	query := sqlres.RegisterMigrationQuery(m.TableName)
+	if migration.ID.IsRepeatable() {
+		query = sqlres.RegisterRepeatableQuery(m.TableName)
+	}
 
 	args := pgx.NamedArgs{
 		"id":               migration.ID,
@@ -84,3 +196,17 @@ func (m *Migrations) Insert(ctx context.Context, conn *pgx.Conn, migration *Migr
 
 	return nil
 }
+
+func (m *Migrations) Delete(ctx context.Context, conn *pgx.Conn, id source.ID) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", m.sanitizedTableName())
+
+	if _, err := conn.Exec(ctx, query, id); err != nil {
+		return &ExecSQLError{Cause: err, SQL: query}
+	}
+
+	return nil
+}
+
+func (m *Migrations) sanitizedTableName() string {
+	return sqlres.ParseTable(m.TableName).Sanitize()
+}
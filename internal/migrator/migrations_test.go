@@ -3,7 +3,9 @@ package migrator_test
 import (
 	"testing"
 
+	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/servletcloud/Andmerada/internal/migrator"
 	"github.com/servletcloud/Andmerada/internal/migrator/sqlres"
 	"github.com/servletcloud/Andmerada/internal/source"
@@ -20,7 +22,7 @@ func TestMigrations_ScanApplied(t *testing.T) {
 	_, err := conn.Exec(t.Context(), sqlres.DDL("migrations"))
 	require.NoError(t, err)
 
-	migrations := &migrator.Migrations{TableName: "migrations"}
+	migrations := &migrator.Migrations{TableName: "migrations", CreateSchema: false}
 
 	scanAppliedMigrations := func(t *testing.T, minID, maxID source.ID) []source.ID {
 		t.Helper()
@@ -60,6 +62,47 @@ func TestMigrations_ScanApplied(t *testing.T) {
 	})
 }
 
+//nolint:paralleltest
+func TestMigrations_Insert(t *testing.T) {
+	connectionURL := tests.StartEmbeddedPostgres(t)
+	conn := tests.OpenPgConnection(t, connectionURL)
+
+	_, err := conn.Exec(t.Context(), sqlres.DDL("migrations"))
+	require.NoError(t, err)
+
+	migrations := &migrator.Migrations{TableName: "migrations", CreateSchema: false}
+
+	newMigration := func(id source.ID, upSQL string) *migrator.Migration {
+		src := source.Source{UpSQL: upSQL} //nolint:exhaustruct
+		src.Configuration.Name = "Insert"
+
+		return migrator.NewMigration(id, &src, 0)
+	}
+
+	t.Run("registering a versioned migration twice fails", func(t *testing.T) {
+		require.NoError(t, migrations.Insert(t.Context(), conn, newMigration(20250701101010, "SELECT 1;")))
+
+		err := migrations.Insert(t.Context(), conn, newMigration(20250701101010, "SELECT 2;"))
+
+		var pgErr *pgconn.PgError
+
+		require.ErrorAs(t, err, &pgErr)
+		assert.Equal(t, pgerrcode.UniqueViolation, pgErr.Code)
+	})
+
+	t.Run("registering a repeatable migration again updates its row", func(t *testing.T) {
+		id := source.MinRepeatableID + 1
+
+		require.NoError(t, migrations.Insert(t.Context(), conn, newMigration(id, "SELECT 1;")))
+		require.NoError(t, migrations.Insert(t.Context(), conn, newMigration(id, "SELECT 2;")))
+
+		var sqlUp string
+
+		require.NoError(t, conn.QueryRow(t.Context(), "SELECT sql_up FROM migrations WHERE id = $1", id).Scan(&sqlUp))
+		assert.Equal(t, "SELECT 2;", sqlUp)
+	})
+}
+
 func insertDummyMigration(t *testing.T, conn *pgx.Conn, id uint64) {
 	t.Helper()
 
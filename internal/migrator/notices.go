@@ -0,0 +1,75 @@
+package migrator
+
+import (
+	"cmp"
+	"log"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const noticeSeverityWarning = "WARNING"
+
+// Notice is a message that PostgreSQL sent while a migration ran, e.g. by RAISE NOTICE
+// or a WARNING of an ALTER statement.
+type Notice struct {
+	Severity string
+	SQLState string
+	Message  string
+	Detail   string
+	Hint     string
+}
+
+func (notice *Notice) IsWarning() bool {
+	return notice.Severity == noticeSeverityWarning
+}
+
+// noticeTarget is the migration whose SQL is running, so that its notices are attributed to it.
+type noticeTarget struct {
+	name  string
+	entry *ReportEntry
+}
+
+// handleNotice is the OnNotice handler of the connections of the applier. Notices outside
+// of a migration, e.g. "relation already exists, skipping" of the bookkeeping, are ignored.
+func (applier *applier) handleNotice(_ *pgconn.PgConn, pgNotice *pgconn.Notice) {
+	target := applier.noticeTarget
+	if target.entry == nil {
+		return
+	}
+
+	notice := Notice{
+		Severity: cmp.Or(pgNotice.SeverityUnlocalized, pgNotice.Severity),
+		SQLState: pgNotice.Code,
+		Message:  pgNotice.Message,
+		Detail:   pgNotice.Detail,
+		Hint:     pgNotice.Hint,
+	}
+
+	log.Printf("  %s in %q: %s", notice.Severity, target.name, notice.Message)
+
+	target.entry.Notices = append(target.entry.Notices, notice)
+}
+
+// collectNotices attributes the notices to the migration until the returned function is called.
+func (applier *applier) collectNotices(ref sourceRef, entry *ReportEntry) func() {
+	applier.noticeTarget = noticeTarget{name: ref.name, entry: entry}
+
+	return func() {
+		applier.noticeTarget = noticeTarget{name: "", entry: nil}
+	}
+}
+
+// checkWarnings returns WarningError with `fail_on_warning` once a migration has raised a warning.
+// rolledBack tells whether the failure undoes the migration, which is the case before its COMMIT.
+func (applier *applier) checkWarnings(ref sourceRef, entry *ReportEntry, rolledBack bool) error {
+	if !applier.failOnWarning {
+		return nil
+	}
+
+	warnings := entry.Warnings()
+	if len(warnings) == 0 {
+		return nil
+	}
+
+	return &WarningError{Name: ref.name, Warnings: warnings, RolledBack: rolledBack}
+}
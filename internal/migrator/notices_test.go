@@ -0,0 +1,105 @@
+package migrator_test
+
+import (
+	"testing"
+
+	"github.com/servletcloud/Andmerada/internal/migrator"
+	"github.com/servletcloud/Andmerada/internal/settings"
+	"github.com/servletcloud/Andmerada/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:paralleltest,funlen
+func TestNotices(t *testing.T) {
+	connectionURL := tests.StartEmbeddedPostgres(t)
+	conn := tests.OpenPgConnection(t, connectionURL)
+
+	newOptions := func(dir string, failOnWarning bool) migrator.ApplyOptions {
+		options := newApplyOptions(connectionURL, dir)
+		options.Project.Configuration.FailOnWarning = failOnWarning
+		options.AllowDrift = true
+
+		return options
+	}
+
+	raise := func(level string, message string) string {
+		return "DO $$ BEGIN RAISE " + level + " '" + message + "'; END $$;"
+	}
+
+	t.Run("Captures the notices of each migration into the report", func(t *testing.T) {
+		dir := t.TempDir()
+
+		first := tests.CreateSource(t, dir, "Backfill", "20250801101010")
+		writeUpSQL(t, first.FullPath, raise("NOTICE", "processed 10 rows")+"\n"+raise("WARNING", "slow backfill"))
+
+		second := tests.CreateSource(t, dir, "Quiet", "20250802101010")
+		writeUpSQL(t, second.FullPath, "CREATE TABLE IF NOT EXISTS quiet (id INTEGER);")
+
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+		require.NoError(t, migrator.ApplyPending(t.Context(), newOptions(dir, false), &report))
+
+		expected := []migrator.Notice{
+			{Severity: "NOTICE", SQLState: "00000", Message: "processed 10 rows", Detail: "", Hint: ""},
+			{Severity: "WARNING", SQLState: "01000", Message: "slow backfill", Detail: "", Hint: ""},
+		}
+		assert.Equal(t, expected, report.Entries[0].Notices)
+		assert.Empty(t, report.Entries[1].Notices)
+	})
+
+	t.Run("fail_on_warning stops after the migration that raised a warning", func(t *testing.T) {
+		// The default transaction mode is none, so the migration is committed before the warnings are checked.
+		dir := t.TempDir()
+
+		first := tests.CreateSource(t, dir, "Warns", "20250803101010")
+		writeUpSQL(t, first.FullPath, raise("WARNING", "identifier truncated"))
+
+		second := tests.CreateSource(t, dir, "Next", "20250804101010")
+		writeUpSQL(t, second.FullPath, raise("NOTICE", "next"))
+
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+		err := migrator.ApplyPending(t.Context(), newOptions(dir, true), &report)
+
+		var warningErr *migrator.WarningError
+
+		require.ErrorAs(t, err, &warningErr)
+		assert.Equal(t, "20250803101010_warns", warningErr.Name)
+		assert.False(t, warningErr.RolledBack)
+		assert.Equal(t, migrator.EntryApplied, report.Entries[0].Status)
+		assert.Equal(t, migrator.EntryPending, report.Entries[1].Status)
+	})
+
+	t.Run("fail_on_warning rolls back a migration with transaction: auto", func(t *testing.T) {
+		dir := t.TempDir()
+
+		warns := tests.CreateSource(t, dir, "Warns in transaction", "20250806101010")
+		writeUpSQL(t, warns.FullPath, "CREATE TABLE warned (id INTEGER);\n"+raise("WARNING", "identifier truncated"))
+
+		options := newOptions(dir, true)
+		options.Project.Configuration.Transaction = settings.TransactionModeAuto
+
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+		err := migrator.ApplyPending(t.Context(), options, &report)
+
+		var warningErr *migrator.WarningError
+
+		require.ErrorAs(t, err, &warningErr)
+		assert.True(t, warningErr.RolledBack)
+		assert.Equal(t, migrator.EntryFailed, report.Entries[0].Status)
+
+		tests.AssertPgTableNotExist(t, conn, "warned")
+		assertMigrationNotRegistered(t, conn, 20250806101010)
+	})
+
+	t.Run("Notices do not fail the run with fail_on_warning", func(t *testing.T) {
+		dir := t.TempDir()
+
+		only := tests.CreateSource(t, dir, "Progress", "20250805101010")
+		writeUpSQL(t, only.FullPath, raise("NOTICE", "progress"))
+
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+		require.NoError(t, migrator.ApplyPending(t.Context(), newOptions(dir, true), &report))
+
+		assert.Len(t, report.Entries[0].Notices, 1)
+	})
+}
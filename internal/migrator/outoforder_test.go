@@ -0,0 +1,68 @@
+package migrator_test
+
+import (
+	"testing"
+
+	"github.com/servletcloud/Andmerada/internal/migrator"
+	"github.com/servletcloud/Andmerada/internal/settings"
+	"github.com/servletcloud/Andmerada/internal/source"
+	"github.com/servletcloud/Andmerada/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:paralleltest,funlen
+func TestApplyPendingOutOfOrder(t *testing.T) {
+	connectionURL := tests.StartEmbeddedPostgres(t)
+	conn := tests.OpenPgConnection(t, connectionURL)
+	dir := t.TempDir()
+
+	newOptions := func(policy settings.OutOfOrderPolicy) migrator.ApplyOptions {
+		options := newApplyOptions(connectionURL, dir)
+		options.OutOfOrder = policy
+
+		return options
+	}
+
+	latest := tests.CreateSource(t, dir, "Latest", "20250510101010")
+	writeUpSQL(t, latest.FullPath, "CREATE TABLE latest (id INTEGER);")
+
+	report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+	require.NoError(t, migrator.ApplyPending(t.Context(), newOptions(settings.OutOfOrderFail), &report))
+	assert.Empty(t, report.OutOfOrderIDs)
+
+	older := tests.CreateSource(t, dir, "Older", "20250501101010")
+	writeUpSQL(t, older.FullPath, "CREATE TABLE older (id INTEGER);")
+
+	newer := tests.CreateSource(t, dir, "Newer", "20250520101010")
+	writeUpSQL(t, newer.FullPath, "CREATE TABLE newer (id INTEGER);")
+
+	latestID := source.NewIDFromString("20250510101010")
+	olderID := source.NewIDFromString("20250501101010")
+
+	t.Run("fail aborts before anything runs", func(t *testing.T) {
+		err := migrator.ApplyPending(t.Context(), newOptions(settings.OutOfOrderFail), &report)
+
+		var outOfOrderErr *migrator.OutOfOrderError
+
+		require.ErrorAs(t, err, &outOfOrderErr)
+		assert.Equal(t, []source.ID{olderID}, outOfOrderErr.IDs)
+		assert.Equal(t, latestID, outOfOrderErr.LatestAppliedID)
+		assert.Equal(t, []source.ID{olderID}, report.OutOfOrderIDs)
+		assert.Equal(t, latestID, report.LatestAppliedID)
+
+		tests.AssertPgTableNotExist(t, conn, "older")
+		tests.AssertPgTableNotExist(t, conn, "newer")
+	})
+
+	t.Run("warn applies the migrations and reports them", func(t *testing.T) {
+		err := migrator.ApplyPending(t.Context(), newOptions(settings.OutOfOrderWarn), &report)
+		require.NoError(t, err)
+
+		assert.Equal(t, []source.ID{olderID}, report.OutOfOrderIDs)
+		assert.Equal(t, latestID, report.LatestAppliedID)
+
+		tests.AssertPgTableExist(t, conn, "older")
+		tests.AssertPgTableExist(t, conn, "newer")
+	})
+}
@@ -0,0 +1,25 @@
+package migrator
+
+import (
+	"github.com/servletcloud/Andmerada/internal/placeholder"
+	"github.com/servletcloud/Andmerada/internal/source"
+)
+
+// migrationPlaceholders adds the built-in placeholders of the migration to the configured values.
+func migrationPlaceholders(
+	values placeholder.Values,
+	id source.ID,
+	name string,
+	migrationsTable string,
+) placeholder.Values {
+	return placeholder.Merge(values, placeholder.Values{
+		placeholder.MigrationID:     id.String(),
+		placeholder.MigrationName:   name,
+		placeholder.MigrationsTable: migrationsTable,
+	})
+}
+
+// projectPlaceholders adds the built-in placeholders that do not depend on a migration, for callbacks.
+func projectPlaceholders(values placeholder.Values, migrationsTable string) placeholder.Values {
+	return placeholder.Merge(values, placeholder.Values{placeholder.MigrationsTable: migrationsTable})
+}
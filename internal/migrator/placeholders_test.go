@@ -0,0 +1,62 @@
+package migrator_test
+
+import (
+	"testing"
+
+	"github.com/servletcloud/Andmerada/internal/migrator"
+	"github.com/servletcloud/Andmerada/internal/placeholder"
+	"github.com/servletcloud/Andmerada/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:paralleltest,funlen
+func TestApplyPendingPlaceholders(t *testing.T) {
+	connectionURL := tests.StartEmbeddedPostgres(t)
+	conn := tests.OpenPgConnection(t, connectionURL)
+	dir := t.TempDir()
+
+	options := newApplyOptions(connectionURL, dir)
+	options.Project.Configuration.Placeholders = placeholder.Values{"table_prefix": "yml", "comment": "from andmerada.yml"}
+	options.Placeholders = placeholder.Values{"table_prefix": "set"}
+
+	upSQL := "CREATE TABLE ${table_prefix}_accounts (id INTEGER);\n" +
+		"COMMENT ON TABLE ${table_prefix}_accounts IS '${comment} ${migration_id} ${migration_name} ${migrations_table}';"
+
+	result := tests.CreateSource(t, dir, "Create accounts", "20251210101010")
+	writeUpSQL(t, result.FullPath, upSQL)
+
+	t.Run("Placeholders are resolved with overrides and built-ins", func(t *testing.T) {
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+		require.NoError(t, migrator.ApplyPending(t.Context(), options, &report))
+
+		tests.AssertPgTableExist(t, conn, "set_accounts")
+
+		var comment, checksum string
+
+		row := conn.QueryRow(t.Context(), "SELECT obj_description('set_accounts'::regclass, 'pg_class')")
+		require.NoError(t, row.Scan(&comment))
+		assert.Equal(t, "from andmerada.yml 20251210101010 Create accounts migrations", comment)
+
+		row = conn.QueryRow(t.Context(), "SELECT sql_up_sha256 FROM migrations WHERE id = 20251210101010")
+		require.NoError(t, row.Scan(&checksum))
+		assert.Equal(t, migrator.Sha256ToHexStr(upSQL), checksum, "the checksum is computed on the raw file")
+	})
+
+	t.Run("Unresolved placeholders fail pre-validation", func(t *testing.T) {
+		applied := tests.CreateSource(t, dir, "Create orders", "20251211101010")
+		writeUpSQL(t, applied.FullPath, "CREATE TABLE orders (id INTEGER);")
+
+		broken := tests.CreateSource(t, dir, "Grant", "20251212101010")
+		writeUpSQL(t, broken.FullPath, "GRANT SELECT ON orders TO ${app_owner};")
+
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+		err := migrator.ApplyPending(t.Context(), options, &report)
+
+		var unresolvedErr *placeholder.UnresolvedError
+
+		require.ErrorAs(t, err, &unresolvedErr)
+		assert.Equal(t, []string{"app_owner"}, unresolvedErr.Names)
+		tests.AssertPgTableNotExist(t, conn, "orders")
+	})
+}
@@ -0,0 +1,192 @@
+package migrator
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/servletcloud/Andmerada/internal/settings"
+	"github.com/servletcloud/Andmerada/internal/source"
+	"github.com/servletcloud/Andmerada/internal/sqlparse"
+)
+
+const (
+	rehearsalSavepoint = "andmerada_rehearsal"
+	migrationSavepoint = "andmerada_migration"
+)
+
+// rehearseAll executes the pending migrations inside one outer transaction that is always
+// rolled back. Each migration runs in its own savepoint, so a failed migration is reported
+// and the rehearsal continues with the next one. Callbacks never run in a rehearsal.
+func (applier *applier) rehearseAll(ctx context.Context, sourceRefs []sourceRef) error {
+	if len(applier.callbacks) > 0 {
+		log.Println("Callbacks are skipped in a rehearsal.")
+	}
+
+	conn := applier.connection.PgConn()
+
+	if err := execSimple(ctx, conn, "BEGIN;"); err != nil {
+		return wrapError(err, ErrTypeRehearsal)
+	}
+
+	defer func() {
+		if err := execSimple(ctx, conn, "ROLLBACK;"); err != nil {
+			log.Println("Failed to roll back the rehearsal:", err)
+		}
+	}()
+
+	source := source.Source{} //nolint:exhaustruct
+
+	for i, ref := range sourceRefs {
+		entry := &applier.report.Entries[i]
+
+		if err := applier.loadSource(ref, &source); err != nil {
+			entry.fail(err)
+
+			return wrapError(err, ErrTypeLoadMigration)
+		}
+
+		sql, err := applier.upSQL(ref, &source)
+		if err != nil {
+			entry.fail(err)
+
+			return wrapError(err, ErrTypeLoadMigration)
+		}
+
+		if err := applier.rehearseMigration(ctx, sql, &source, ref, entry); err != nil {
+			return wrapError(err, ErrTypeRehearsal)
+		}
+	}
+
+	log.Println("Rolled back all rehearsed migrations.")
+
+	if err := checkRehearsals(applier.report.Entries); err != nil {
+		return err
+	}
+
+	for i, ref := range sourceRefs {
+		if err := applier.checkWarnings(ref, &applier.report.Entries[i], true); err != nil {
+			return wrapError(err, ErrTypeWarning)
+		}
+	}
+
+	return nil
+}
+
+// rehearseMigration records the outcome of the migration in the entry. Like a real run, the migration
+// runs with its session settings and timeout. The returned error means that the rehearsal cannot continue.
+func (applier *applier) rehearseMigration(
+	ctx context.Context,
+	sql string,
+	source *source.Source,
+	ref sourceRef,
+	entry *ReportEntry,
+) error {
+	rehearsalSQL, ok := toRehearsalSQL(sql)
+	if !ok {
+		log.Printf("Skipped    %q: it cannot run inside a transaction, so it cannot be rehearsed", ref.name)
+
+		entry.Status = EntryNotRehearsable
+
+		return nil
+	}
+
+	log.Printf("Rehearsing %q, please wait...", ref.name)
+
+	session := settings.ResolveSession(applier.session, source.Configuration.Session)
+
+	previous, err := applySession(ctx, applier.connection, sessionParameters(session, applicationNamePrefix+ref.name))
+	if err != nil {
+		return err
+	}
+
+	conn := applier.connection.PgConn()
+	stopNotices := applier.collectNotices(ref, entry)
+	timeoutCtx, cancel := withMigrationTimeout(ctx, session.Timeout)
+	startTime := time.Now()
+	err = execSimple(timeoutCtx, conn, rehearsalSQL)
+	entry.Duration = time.Since(startTime)
+
+	cancel()
+	stopNotices()
+
+	durationStr := humanizeDuration(entry.Duration, "0ms")
+
+	if err == nil {
+		log.Printf("Rehearsed  %q in %s", ref.name, durationStr)
+
+		entry.Status = EntryRehearsed
+
+		return restoreRehearsalSession(ctx, applier.connection, previous)
+	}
+
+	log.Printf("Failed     %q in %s: %v", ref.name, durationStr, err)
+
+	entry.fail(timeoutError(ctx, timeoutCtx, session.Timeout, &ExecSQLError{Cause: err, SQL: rehearsalSQL}))
+
+	if err := execSimple(ctx, conn, "ROLLBACK TO SAVEPOINT "+rehearsalSavepoint+";"); err != nil {
+		return err
+	}
+
+	return restoreRehearsalSession(ctx, applier.connection, previous)
+}
+
+// restoreRehearsalSession sets the parameters back to the values returned by applySession. Unlike restoreSession,
+// it keeps the transaction of the rehearsal open.
+func restoreRehearsalSession(ctx context.Context, conn *pgx.Conn, previous []sessionParameter) error {
+	for _, parameter := range previous {
+		if err := setSessionParameter(ctx, conn, parameter); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// toRehearsalSQL wraps the SQL in a savepoint and translates its own transaction control
+// into a nested savepoint. It returns false for SQL that cannot run inside a transaction.
+func toRehearsalSQL(sql string) (string, bool) {
+	script := sqlparse.Parse(sql)
+
+	if script.HasNonTransactional() {
+		return "", false
+	}
+
+	for i := range script.Statements {
+		if script.Statements[i].Kind() == sqlparse.KindTwoPhaseCommit {
+			return "", false
+		}
+	}
+
+	rewritten := sqlparse.Rewrite(sql, script.Statements, func(statement *sqlparse.Statement) (string, bool) {
+		switch statement.Kind() { //nolint:exhaustive
+		case sqlparse.KindBegin:
+			return "SAVEPOINT " + migrationSavepoint + ";", true
+		case sqlparse.KindCommit:
+			return "RELEASE SAVEPOINT " + migrationSavepoint + ";", true
+		case sqlparse.KindRollback:
+			return "ROLLBACK TO SAVEPOINT " + migrationSavepoint + "; RELEASE SAVEPOINT " + migrationSavepoint + ";", true
+		}
+
+		return "", false
+	})
+
+	return "SAVEPOINT " + rehearsalSavepoint + ";\n" + rewritten + "\n;\nRELEASE SAVEPOINT " + rehearsalSavepoint + ";", true
+}
+
+func checkRehearsals(entries []ReportEntry) error {
+	var failed []ReportEntry
+
+	for _, entry := range entries {
+		if entry.Status == EntryFailed {
+			failed = append(failed, entry)
+		}
+	}
+
+	if len(failed) > 0 {
+		return wrapError(&RehearsalFailedError{Failed: failed, Total: len(entries)}, ErrTypeRehearsal)
+	}
+
+	return nil
+}
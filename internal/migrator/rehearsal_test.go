@@ -0,0 +1,61 @@
+package migrator_test
+
+import (
+	"testing"
+
+	"github.com/servletcloud/Andmerada/internal/migrator"
+	"github.com/servletcloud/Andmerada/internal/source"
+	"github.com/servletcloud/Andmerada/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:paralleltest,funlen
+func TestApplyPendingRehearse(t *testing.T) {
+	connectionURL := tests.StartEmbeddedPostgres(t)
+	conn := tests.OpenPgConnection(t, connectionURL)
+	dir := t.TempDir()
+
+	options := newApplyOptions(connectionURL, dir)
+	options.Rehearse = true
+
+	createMigration := func(t *testing.T, title, timestamp, upSQL string) {
+		t.Helper()
+
+		result := tests.CreateSource(t, dir, title, timestamp)
+		writeUpSQL(t, result.FullPath, upSQL)
+	}
+
+	createMigration(t, "Explicit transaction", "20250601101010", "BEGIN;\nCREATE TABLE rehearsal_1 (id INTEGER);\nCOMMIT;")
+	createMigration(t, "Broken", "20250602101010", "CREATE TABLE rehearsal_2 (id INTEGER);\nINSERT INTO missing VALUES (1);")
+	createMigration(t, "Concurrently", "20250603101010", "CREATE INDEX CONCURRENTLY idx ON rehearsal_1 (id);")
+	createMigration(t, "Depends on the first", "20250604101010", "ALTER TABLE rehearsal_1 ADD COLUMN name TEXT;")
+
+	report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+	err := migrator.ApplyPending(t.Context(), options, &report)
+
+	var rehearsalErr *migrator.RehearsalFailedError
+
+	require.ErrorAs(t, err, &rehearsalErr)
+	require.Len(t, rehearsalErr.Failed, 1)
+	assert.Equal(t, source.NewIDFromString("20250602101010"), rehearsalErr.Failed[0].ID)
+	assert.Equal(t, 4, rehearsalErr.Total)
+
+	statuses := make([]migrator.EntryStatus, 0, len(report.Entries))
+	for _, entry := range report.Entries {
+		statuses = append(statuses, entry.Status)
+	}
+
+	assert.Equal(t, []migrator.EntryStatus{
+		migrator.EntryRehearsed,
+		migrator.EntryFailed,
+		migrator.EntryNotRehearsable,
+		migrator.EntryRehearsed,
+	}, statuses)
+	assert.Equal(t, "42P01", report.Entries[1].SQLState)
+	assert.Positive(t, report.Entries[1].Position)
+
+	tests.AssertPgTableNotExist(t, conn, "rehearsal_1")
+	tests.AssertPgTableNotExist(t, conn, "rehearsal_2")
+	tests.AssertPgTableNotExist(t, conn, "migrations")
+}
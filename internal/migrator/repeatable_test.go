@@ -0,0 +1,129 @@
+package migrator_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/servletcloud/Andmerada/internal/migrator"
+	"github.com/servletcloud/Andmerada/internal/osutil"
+	"github.com/servletcloud/Andmerada/internal/project"
+	"github.com/servletcloud/Andmerada/internal/source"
+	"github.com/servletcloud/Andmerada/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:paralleltest,funlen
+func TestApplyPendingRepeatable(t *testing.T) {
+	connectionURL := tests.StartEmbeddedPostgres(t)
+	conn := tests.OpenPgConnection(t, connectionURL)
+	dir := t.TempDir()
+	testProject := project.Project{Dir: dir, Configuration: createProjectConfig(), Callbacks: nil}
+
+	options := newApplyOptions(connectionURL, dir)
+
+	statusOf := func(t *testing.T) map[string]migrator.MigrationState {
+		t.Helper()
+
+		options := migrator.StatusOptions{MaxSQLFileSize: 1024, DatabaseURL: string(connectionURL), Project: testProject}
+		report := migrator.StatusReport{Entries: nil}
+
+		require.NoError(t, migrator.Status(t.Context(), options, &report))
+
+		result := make(map[string]migrator.MigrationState)
+		for _, entry := range report.Entries {
+			result[entry.Name] = entry.State
+		}
+
+		return result
+	}
+
+	repeatableDir := filepath.Join(dir, source.RepeatableDirName)
+	require.NoError(t, os.Mkdir(repeatableDir, osutil.DirPerm0755))
+
+	writeRepeatable := func(t *testing.T, name, sql string) {
+		t.Helper()
+
+		require.NoError(t, os.WriteFile(filepath.Join(repeatableDir, name), []byte(sql), osutil.FilePerm0644))
+	}
+
+	result := tests.CreateSource(t, dir, "Create accounts", "20251101101010")
+	writeUpSQL(t, result.FullPath, "CREATE TABLE accounts (id INTEGER, name TEXT);")
+
+	// The views are applied in name order, so b_ may depend on a_.
+	writeRepeatable(t, "a_accounts_view.sql", "CREATE OR REPLACE VIEW accounts_view AS SELECT id FROM accounts;")
+	writeRepeatable(t, "b_accounts_count.sql",
+		"CREATE OR REPLACE VIEW accounts_count AS SELECT COUNT(*) AS total FROM accounts_view;")
+
+	t.Run("Repeatables are applied after versioned migrations", func(t *testing.T) {
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+		require.NoError(t, migrator.ApplyPending(t.Context(), options, &report))
+
+		require.Len(t, report.Entries, 3)
+		assert.Equal(t, result.BaseDir, report.Entries[0].Name)
+		assert.Equal(t, "repeatable/a_accounts_view.sql", report.Entries[1].Name)
+		assert.Equal(t, "repeatable/b_accounts_count.sql", report.Entries[2].Name)
+		assert.Equal(t, 3, report.CountOf(migrator.EntryApplied))
+
+		tests.AssertPgTableExist(t, conn, "accounts_count")
+	})
+
+	t.Run("Unchanged repeatables are not re-applied", func(t *testing.T) {
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+		require.NoError(t, migrator.ApplyPending(t.Context(), options, &report))
+
+		assert.Equal(t, 0, report.PendingCount)
+		assert.Equal(t, migrator.StateApplied, statusOf(t)["repeatable/a_accounts_view.sql"])
+	})
+
+	t.Run("A changed repeatable is re-applied", func(t *testing.T) {
+		writeRepeatable(t, "a_accounts_view.sql", "CREATE OR REPLACE VIEW accounts_view AS SELECT id, name FROM accounts;")
+
+		status := statusOf(t)
+		assert.Equal(t, migrator.StateOutdated, status["repeatable/a_accounts_view.sql"])
+		assert.Equal(t, migrator.StateApplied, status["repeatable/b_accounts_count.sql"])
+
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+		require.NoError(t, migrator.ApplyPending(t.Context(), options, &report))
+
+		require.Len(t, report.Entries, 1)
+		assert.Equal(t, "repeatable/a_accounts_view.sql", report.Entries[0].Name)
+		assert.Equal(t, migrator.StateApplied, statusOf(t)["repeatable/a_accounts_view.sql"])
+	})
+
+	t.Run("Repeatables wait for versioned migrations left behind", func(t *testing.T) {
+		email := tests.CreateSource(t, dir, "Add email", "20251102101010")
+		tests.CreateSource(t, dir, "Add phone", "20251103101010")
+		writeRepeatable(t, "c_new_view.sql", "CREATE OR REPLACE VIEW new_view AS SELECT 1 AS one;")
+
+		limited := options
+		limited.Limit = 1
+
+		// The limit leaves "Add phone" pending, so the new repeatable waits for it.
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+		require.NoError(t, migrator.ApplyPending(t.Context(), limited, &report))
+
+		require.Len(t, report.Entries, 1)
+		assert.Equal(t, email.BaseDir, report.Entries[0].Name)
+		assert.Equal(t, migrator.StatePending, statusOf(t)["repeatable/c_new_view.sql"])
+	})
+
+	t.Run("Repeatables are not rolled back", func(t *testing.T) {
+		rollbackOptions := migrator.RollbackOptions{
+			MaxSQLFileSize: 1024,
+			DatabaseURL:    string(connectionURL),
+			Project:        testProject,
+			Count:          1,
+			ToID:           source.EmptyMigrationID,
+			DownSQLSource:  migrator.DownSQLFromDatabase,
+			DryRun:         false,
+			LockTimeout:    migrator.DefaultLockTimeout,
+			Placeholders:   nil,
+		}
+
+		require.NoError(t, migrator.Rollback(t.Context(), rollbackOptions, &migrator.RollbackReport{TargetCount: 0}))
+		assert.Equal(t, migrator.StatePending, statusOf(t)["Add email"])
+		assert.Equal(t, migrator.StateApplied, statusOf(t)["repeatable/b_accounts_count.sql"])
+	})
+}
@@ -0,0 +1,93 @@
+package migrator
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/servletcloud/Andmerada/internal/source"
+)
+
+type EntryStatus string
+
+const (
+	// EntryPending is a migration that was not executed, e.g. in dry run mode or after an earlier failure.
+	EntryPending        EntryStatus = "pending"
+	EntryApplied        EntryStatus = "applied"
+	EntryFailed         EntryStatus = "failed"
+	EntryRehearsed      EntryStatus = "rehearsed"
+	EntryNotRehearsable EntryStatus = "not-rehearsable"
+)
+
+type Report struct {
+	PendingCount    int
+	LatestAppliedID source.ID
+	OutOfOrderIDs   []source.ID
+	SkippedIDs      []source.ID
+	Entries         []ReportEntry
+}
+
+// ReportEntry is the outcome of one pending migration. SQLState and Position are set when
+// the migration failed with a PostgreSQL error; Position is 1-based in the executed SQL.
+// Notices are the messages PostgreSQL sent while the migration ran, including failed attempts.
+type ReportEntry struct {
+	ID       source.ID
+	Name     string
+	Status   EntryStatus
+	Duration time.Duration
+	Err      error
+	SQLState string
+	Position int
+	Notices  []Notice
+}
+
+func (report *Report) addPendingEntries(sourceRefs []sourceRef) {
+	for _, ref := range sourceRefs {
+		report.Entries = append(report.Entries, ReportEntry{
+			ID:       ref.id,
+			Name:     ref.name,
+			Status:   EntryPending,
+			Duration: 0,
+			Err:      nil,
+			SQLState: "",
+			Position: 0,
+			Notices:  nil,
+		})
+	}
+}
+
+func (report *Report) CountOf(status EntryStatus) int {
+	count := 0
+
+	for _, entry := range report.Entries {
+		if entry.Status == status {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (entry *ReportEntry) Warnings() []Notice {
+	var warnings []Notice
+
+	for _, notice := range entry.Notices {
+		if notice.IsWarning() {
+			warnings = append(warnings, notice)
+		}
+	}
+
+	return warnings
+}
+
+func (entry *ReportEntry) fail(err error) {
+	entry.Status = EntryFailed
+	entry.Err = err
+
+	var pgError *pgconn.PgError
+
+	if errors.As(err, &pgError) {
+		entry.SQLState = pgError.Code
+		entry.Position = int(pgError.Position)
+	}
+}
@@ -0,0 +1,46 @@
+package migrator_test
+
+import (
+	"testing"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/servletcloud/Andmerada/internal/migrator"
+	"github.com/servletcloud/Andmerada/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:paralleltest
+func TestApplyPendingReportEntries(t *testing.T) {
+	connectionURL := tests.StartEmbeddedPostgres(t)
+	dir := t.TempDir()
+
+	options := newApplyOptions(connectionURL, dir)
+
+	first := tests.CreateSource(t, dir, "First", "20250701101010")
+	writeUpSQL(t, first.FullPath, "CREATE TABLE report_1 (id INTEGER);")
+
+	second := tests.CreateSource(t, dir, "Second", "20250702101010")
+	writeUpSQL(t, second.FullPath, "CREATE TABLE report_2 (id INTEGER);\nINSERT INTO missing VALUES (1);")
+
+	third := tests.CreateSource(t, dir, "Third", "20250703101010")
+	writeUpSQL(t, third.FullPath, "CREATE TABLE report_3 (id INTEGER);")
+
+	report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+	err := migrator.ApplyPending(t.Context(), options, &report)
+	require.Error(t, err)
+
+	require.Len(t, report.Entries, 3)
+
+	assert.Equal(t, first.BaseDir, report.Entries[0].Name)
+	assert.Equal(t, migrator.EntryApplied, report.Entries[0].Status)
+	require.NoError(t, report.Entries[0].Err)
+
+	assert.Equal(t, migrator.EntryFailed, report.Entries[1].Status)
+	assert.Equal(t, pgerrcode.UndefinedTable, report.Entries[1].SQLState)
+	assert.Equal(t, 49, report.Entries[1].Position)
+	require.Error(t, report.Entries[1].Err)
+
+	assert.Equal(t, migrator.EntryPending, report.Entries[2].Status)
+	assert.Equal(t, 2, report.CountOf(migrator.EntryApplied)+report.CountOf(migrator.EntryFailed))
+}
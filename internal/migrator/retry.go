@@ -0,0 +1,127 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/servletcloud/Andmerada/internal/settings"
+	"github.com/servletcloud/Andmerada/internal/source"
+	"github.com/servletcloud/Andmerada/internal/sqlparse"
+)
+
+// applyMigrationWithRetry applies the migration and retries it according to the retry policy.
+// Only migrations that run as a single transaction are retried, because a failure
+// of such a migration is guaranteed to leave no changes behind.
+func (applier *applier) applyMigrationWithRetry(
+	ctx context.Context,
+	sql string,
+	src *source.Source,
+	ref sourceRef,
+	beforeCommit func(ctx context.Context) error,
+) (time.Duration, error) {
+	policy := settings.ResolveRetryPolicy(applier.retry, src.Configuration.Retry)
+	script := sqlparse.Parse(sql)
+	retryable := isSingleTransaction(applier.transactionMode(src), applier.executionMode(src), &script)
+
+	for attempt := 1; ; attempt++ {
+		startedAt := time.Now()
+		duration, err := applier.applyMigration(ctx, sql, src, ref, beforeCommit)
+
+		applier.recordAttempt(ctx, ref, src, startedAt, err)
+
+		if err == nil {
+			return duration, nil
+		}
+
+		if !retryable || attempt >= policy.MaxAttempts || !applier.isTransientError(err, &policy) {
+			return duration, err
+		}
+
+		backoff := policy.Backoff(attempt)
+
+		log.Printf(
+			"Attempt %d of %d to apply %q failed: %v. Retrying in %s...",
+			attempt, policy.MaxAttempts, ref.name, err, humanizeDuration(backoff, "0ms"),
+		)
+
+		if err := sleep(ctx, backoff); err != nil {
+			return duration, err
+		}
+
+		connectionLost := applier.connection.IsClosed()
+
+		if err := applier.recoverConnection(ctx); err != nil {
+			return duration, err
+		}
+
+		if connectionLost {
+			if err := applier.checkNotRegistered(ctx, ref); err != nil {
+				return duration, err
+			}
+		}
+	}
+}
+
+// checkNotRegistered refuses to retry a migration after a lost connection when the migrations table
+// already has it, because then it must not be applied twice.
+func (applier *applier) checkNotRegistered(ctx context.Context, ref sourceRef) error {
+	// A repeatable migration keeps its ID across versions, so its earlier rows say nothing about this attempt.
+	if ref.repeatable {
+		return nil
+	}
+
+	ids, err := applier.migrationsRepo.ScanApplied(ctx, applier.connection, ref.id, ref.id)
+	if err != nil && !isUndefinedTableError(err) {
+		return err
+	}
+
+	if len(ids) > 0 {
+		return &AlreadyRegisteredError{Name: ref.name}
+	}
+
+	return nil
+}
+
+func (applier *applier) isTransientError(err error, policy *settings.RetryPolicy) bool {
+	if timeoutErr := new(MigrationTimeoutError); errors.As(err, &timeoutErr) {
+		return false
+	}
+
+	if applier.connection.IsClosed() {
+		return policy.RetryOnConnectionLoss
+	}
+
+	var pgError *pgconn.PgError
+
+	return errors.As(err, &pgError) && policy.IsRetryableSQLState(pgError.Code)
+}
+
+// recoverConnection prepares the connection for the next attempt. A closed connection is
+// re-established, the migrations lock stays held by the lock connection meanwhile.
+// An aborted transaction is rolled back.
+func (applier *applier) recoverConnection(ctx context.Context) error {
+	if applier.connection.IsClosed() {
+		return applier.connect(ctx)
+	}
+
+	if isConnectionInTransaction(applier.connection.PgConn()) {
+		return execSimple(ctx, applier.connection.PgConn(), "ROLLBACK;")
+	}
+
+	return nil
+}
+
+func sleep(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck
+	case <-timer.C:
+		return nil
+	}
+}
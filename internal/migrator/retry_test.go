@@ -0,0 +1,157 @@
+package migrator_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/servletcloud/Andmerada/internal/migrator"
+	"github.com/servletcloud/Andmerada/internal/settings"
+	"github.com/servletcloud/Andmerada/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:paralleltest,funlen
+func TestApplyPendingRetry(t *testing.T) {
+	connectionURL := tests.StartEmbeddedPostgres(t)
+	conn := tests.OpenPgConnection(t, connectionURL)
+	blockerConn := tests.OpenPgConnection(t, connectionURL)
+
+	_, err := conn.Exec(t.Context(), "CREATE TABLE accounts (id INTEGER);")
+	require.NoError(t, err)
+
+	maxAttempts := 20
+	backoff := 50 * time.Millisecond
+
+	configuration := createProjectConfig()
+	configuration.Retry = settings.Retry{ //nolint:exhaustruct
+		MaxAttempts:    &maxAttempts,
+		InitialBackoff: &backoff,
+		MaxBackoff:     &backoff,
+	}
+
+	newOptions := func(dir string) migrator.ApplyOptions {
+		options := newApplyOptions(connectionURL, dir)
+		options.Project.Configuration = configuration
+		options.AllowDrift = true
+
+		return options
+	}
+
+	holdTableLock := func(t *testing.T) {
+		t.Helper()
+
+		_, err := blockerConn.Exec(t.Context(), "BEGIN; LOCK TABLE accounts IN ACCESS EXCLUSIVE MODE;")
+		require.NoError(t, err)
+	}
+
+	releaseTableLock := func(t *testing.T) {
+		t.Helper()
+
+		_, err := blockerConn.Exec(t.Context(), "ROLLBACK;")
+		require.NoError(t, err)
+	}
+
+	t.Run("A single-transaction migration is retried until the lock is released", func(t *testing.T) {
+		dir := t.TempDir()
+		result := tests.CreateSource(t, dir, "Add email", "20250301101010")
+		writeUpSQL(t, result.FullPath, `
+			BEGIN;
+			SET LOCAL lock_timeout = '50ms';
+			ALTER TABLE accounts ADD COLUMN email TEXT;
+			COMMIT;
+		`)
+
+		holdTableLock(t)
+
+		released := make(chan struct{})
+
+		go func() {
+			defer close(released)
+
+			time.Sleep(300 * time.Millisecond)
+			_, _ = blockerConn.Exec(t.Context(), "ROLLBACK;")
+		}()
+
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+		err := migrator.ApplyPending(t.Context(), newOptions(dir), &report)
+
+		<-released
+
+		require.NoError(t, err)
+
+		var count int
+
+		row := conn.QueryRow(t.Context(),
+			"SELECT COUNT(*) FROM information_schema.columns WHERE table_name = 'accounts' AND column_name = 'email'")
+		require.NoError(t, row.Scan(&count))
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("A migration with several transactions is not retried", func(t *testing.T) {
+		dir := t.TempDir()
+		result := tests.CreateSource(t, dir, "Add phone", "20250302101010")
+		writeUpSQL(t, result.FullPath, `
+			BEGIN;
+			SET LOCAL lock_timeout = '50ms';
+			ALTER TABLE accounts ADD COLUMN phone TEXT;
+			COMMIT;
+			BEGIN;
+			SET LOCAL lock_timeout = '50ms';
+			ALTER TABLE accounts ADD COLUMN fax TEXT;
+			COMMIT;
+		`)
+
+		holdTableLock(t)
+
+		t.Cleanup(func() {
+			releaseTableLock(t)
+		})
+
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+		err := migrator.ApplyPending(t.Context(), newOptions(dir), &report)
+
+		var pgError *pgconn.PgError
+
+		require.ErrorAs(t, err, &pgError)
+		assert.Equal(t, pgerrcode.LockNotAvailable, pgError.Code)
+	})
+
+	t.Run("A lost connection is retried only when retry_on_connection_loss is set", func(t *testing.T) {
+		_, err := conn.Exec(t.Context(), "CREATE SEQUENCE attempts_seq;")
+		require.NoError(t, err)
+
+		// nextval is not rolled back, so the sequence counts the attempts.
+		dropConnection := "SELECT nextval('public.attempts_seq');\n" +
+			"SELECT pg_terminate_backend(pg_backend_pid());"
+
+		attempts := func(t *testing.T) int {
+			t.Helper()
+
+			var count int
+
+			require.NoError(t, conn.QueryRow(t.Context(), "SELECT last_value FROM attempts_seq").Scan(&count))
+			_, err := conn.Exec(t.Context(), "ALTER SEQUENCE attempts_seq RESTART WITH 1;")
+			require.NoError(t, err)
+
+			return count
+		}
+
+		dir := t.TempDir()
+		result := tests.CreateSource(t, dir, "Drops connection", "20250303101010")
+		writeUpSQL(t, result.FullPath, dropConnection)
+
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+		require.Error(t, migrator.ApplyPending(t.Context(), newOptions(dir), &report))
+		assert.Equal(t, 1, attempts(t))
+
+		retryOnConnectionLoss := true
+		options := newOptions(dir)
+		options.Project.Configuration.Retry.RetryOnConnectionLoss = &retryOnConnectionLoss
+
+		require.Error(t, migrator.ApplyPending(t.Context(), options, &report))
+		assert.Equal(t, maxAttempts, attempts(t))
+	})
+}
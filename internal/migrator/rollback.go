@@ -0,0 +1,430 @@
+package migrator
+
+import (
+	"cmp"
+	"context"
+	"log"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/servletcloud/Andmerada/internal/placeholder"
+	"github.com/servletcloud/Andmerada/internal/project"
+	"github.com/servletcloud/Andmerada/internal/settings"
+	"github.com/servletcloud/Andmerada/internal/source"
+)
+
+type DownSQLSource int
+
+const (
+	DownSQLFromDatabase DownSQLSource = iota
+	DownSQLFromDisk
+)
+
+type RollbackReport struct {
+	TargetCount int
+}
+
+type RollbackOptions struct {
+	MaxSQLFileSize int64
+	DatabaseURL    string
+	Project        project.Project
+	Count          int
+	ToID           source.ID
+	DownSQLSource  DownSQLSource
+	DryRun         bool
+	LockTimeout    time.Duration
+	// Placeholders override the placeholders of andmerada.yml, e.g. the ones from environment variables and --set.
+	Placeholders placeholder.Values
+}
+
+type rollbacker struct {
+	databaseURL   string
+	projectDir    string
+	count         int
+	toID          source.ID
+	downSQLSource DownSQLSource
+	dryRun        bool
+	lockTimeout   time.Duration
+	transaction   settings.TransactionMode
+	execution     settings.ExecutionMode
+	session       settings.Session
+	searchPath    []string
+	placeholders  placeholder.Values
+
+	report         *RollbackReport
+	migrationsRepo *Migrations
+	history        *historyRecorder
+	lock           *Lock
+	loader         source.Loader
+	connection     *pgx.Conn
+}
+
+type rollbackTarget struct {
+	migration   Migration
+	dirName     string
+	downSQL     string
+	transaction settings.TransactionMode
+	execution   settings.ExecutionMode
+	session     settings.Session
+}
+
+func Rollback(ctx context.Context, options RollbackOptions, report *RollbackReport) error {
+	migrationsTable := options.Project.Configuration.MigrationsTableName
+
+	report.TargetCount = 0
+
+	rollbacker := &rollbacker{
+		databaseURL:    options.DatabaseURL,
+		projectDir:     options.Project.Dir,
+		count:          options.Count,
+		toID:           options.ToID,
+		downSQLSource:  options.DownSQLSource,
+		dryRun:         options.DryRun,
+		lockTimeout:    options.LockTimeout,
+		transaction:    options.Project.Configuration.Transaction,
+		execution:      options.Project.Configuration.Execution,
+		session:        options.Project.Configuration.Session,
+		searchPath:     options.Project.Configuration.SearchPath,
+		placeholders:   placeholder.Merge(options.Project.Configuration.Placeholders, options.Placeholders),
+		report:         report,
+		migrationsRepo: &Migrations{TableName: migrationsTable, CreateSchema: options.Project.Configuration.CreateSchema},
+		history:        newHistoryRecorder(migrationsTable, options.Project.Dir),
+		lock:           &Lock{TableName: migrationsTable},
+		loader:         source.Loader{MaxSQLFileSize: options.MaxSQLFileSize},
+		connection:     nil,
+	}
+
+	defer rollbacker.close(ctx)
+
+	return rollbacker.rollback(ctx)
+}
+
+func (rollbacker *rollbacker) rollback(ctx context.Context) error {
+	sourceIDToName, err := source.ScanAll(rollbacker.projectDir)
+	if err != nil {
+		return wrapError(err, ErrTypeListMigrationsOnDisk)
+	}
+
+	if err := rollbacker.connect(ctx); err != nil {
+		return wrapError(err, ErrTypeDBConnect)
+	}
+
+	if err := rollbacker.acquireLock(ctx); err != nil {
+		return wrapError(err, ErrTypeAcquireLock)
+	}
+
+	defer rollbacker.releaseLock(ctx)
+
+	if _, err := checkSchemaVersion(ctx, rollbacker.connection, rollbacker.migrationsRepo); err != nil {
+		return err
+	}
+
+	applied, err := rollbacker.migrationsRepo.ListApplied(ctx, rollbacker.connection)
+	if err != nil && !isUndefinedTableError(err) {
+		return wrapError(err, ErrTypeScanAppliedMigrations)
+	}
+
+	targets, err := rollbacker.selectTargets(applied, sourceIDToName)
+	if err != nil {
+		return wrapError(err, ErrTypeRollbackTarget)
+	}
+
+	rollbacker.report.TargetCount = len(targets)
+
+	if err := rollbacker.ensureNotBlocked(targets); err != nil {
+		return wrapError(err, ErrTypeRollbackBlocked)
+	}
+
+	if err := rollbacker.loadDownSQL(targets); err != nil {
+		return wrapError(err, ErrTypeLoadMigration)
+	}
+
+	if err := rollbacker.manageTransactions(targets); err != nil {
+		return wrapError(err, ErrTypeLoadMigration)
+	}
+
+	return rollbacker.rollbackAll(ctx, targets)
+}
+
+func (rollbacker *rollbacker) selectTargets(
+	applied []Migration,
+	sourceIDToName map[source.ID]string,
+) ([]rollbackTarget, error) {
+	toID := rollbacker.toID
+
+	if toID != source.EmptyMigrationID {
+		found := slices.ContainsFunc(applied, func(migration Migration) bool {
+			return migration.ID == toID
+		})
+
+		if !found {
+			return nil, &RollbackTargetNotAppliedError{ID: toID}
+		}
+	}
+
+	result := make([]rollbackTarget, 0, len(applied))
+
+	for _, migration := range slices.Backward(applied) {
+		if migration.ID <= toID {
+			break
+		}
+
+		if rollbacker.count != NoLimit && len(result) >= rollbacker.count {
+			break
+		}
+
+		result = append(result, rollbackTarget{
+			migration:   migration,
+			dirName:     sourceIDToName[migration.ID],
+			downSQL:     migration.SQLDown,
+			transaction: "",
+			execution:   "",
+			session:     settings.Session{}, //nolint:exhaustruct
+		})
+	}
+
+	return result, nil
+}
+
+func (rollbacker *rollbacker) ensureNotBlocked(targets []rollbackTarget) error {
+	var blocked []BlockedRollback
+
+	for _, target := range targets {
+		configuration, found := rollbacker.loadConfiguration(target)
+
+		blockedOnDisk := found && rollbacker.downSQLSource == DownSQLFromDisk && configuration.Down.Block
+
+		if !target.migration.RollbackBlocked && !blockedOnDisk {
+			continue
+		}
+
+		reason := configuration.Down.BlockReason
+		if !found {
+			reason = "No reason is available because the migration is not found on disk."
+		}
+
+		blocked = append(blocked, BlockedRollback{
+			ID:     target.migration.ID,
+			Name:   target.migration.Name,
+			Reason: reason,
+		})
+	}
+
+	if len(blocked) > 0 {
+		return &RollbackBlockedError{Blocked: blocked}
+	}
+
+	return nil
+}
+
+func (rollbacker *rollbacker) loadConfiguration(target rollbackTarget) (source.Configuration, bool) {
+	configuration := source.Configuration{} //nolint:exhaustruct
+
+	if target.dirName == "" {
+		return configuration, false
+	}
+
+	dir := filepath.Join(rollbacker.projectDir, target.dirName)
+
+	if err := rollbacker.loader.LoadConfiguration(dir, &configuration); err != nil {
+		return configuration, false
+	}
+
+	return configuration, true
+}
+
+func (rollbacker *rollbacker) loadDownSQL(targets []rollbackTarget) error {
+	if rollbacker.downSQLSource != DownSQLFromDisk {
+		return nil
+	}
+
+	source := source.Source{} //nolint:exhaustruct
+
+	for i := range targets {
+		target := &targets[i]
+
+		if target.dirName == "" {
+			return &SourceNotFoundError{ID: target.migration.ID}
+		}
+
+		dir := filepath.Join(rollbacker.projectDir, target.dirName)
+
+		if err := rollbacker.loader.LoadSource(dir, &source); err != nil {
+			return &LoadSourceError{Cause: err, Name: target.dirName}
+		}
+
+		target.downSQL = source.DownSQL
+	}
+
+	return nil
+}
+
+// manageTransactions resolves the placeholders in the down SQL of targets and checks it against the transaction
+// mode, which execMigration applies. The SQL is kept unwrapped, so a dry run prints it as written. The modes and
+// the session settings are taken from migration.yml on disk, or from the project when the migration is not on disk.
+func (rollbacker *rollbacker) manageTransactions(targets []rollbackTarget) error {
+	for i := range targets {
+		target := &targets[i]
+		name := cmp.Or(target.dirName, target.migration.Name)
+		configuration, _ := rollbacker.loadConfiguration(*target)
+		mode := settings.ResolveTransactionMode(rollbacker.transaction, configuration.Transaction)
+		execution := settings.ResolveExecutionMode(rollbacker.execution, configuration.Execution)
+		values := migrationPlaceholders(
+			rollbacker.placeholders,
+			target.migration.ID,
+			target.migration.Name,
+			rollbacker.migrationsRepo.TableName,
+		)
+
+		sql, err := placeholder.Replace(target.downSQL, values)
+		if err != nil {
+			return &LoadSourceError{Cause: err, Name: name}
+		}
+
+		sql, err = prepareSQL(mode, sql)
+		if err != nil {
+			return &LoadSourceError{Cause: err, Name: name}
+		}
+
+		target.downSQL = sql
+		target.transaction = mode
+		target.execution = execution
+		target.session = settings.ResolveSession(rollbacker.session, configuration.Session)
+	}
+
+	return nil
+}
+
+func (rollbacker *rollbacker) rollbackAll(ctx context.Context, targets []rollbackTarget) error {
+	for _, target := range targets {
+		if err := rollbacker.rollbackMigration(ctx, target); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rollbackMigration reverts the migration and removes it from the migrations table. In the auto transaction mode
+// both happen in one transaction. Otherwise the row is deleted after the down SQL, so a failure in between
+// leaves the migration reverted but still registered.
+func (rollbacker *rollbacker) rollbackMigration(ctx context.Context, target rollbackTarget) error {
+	migration := target.migration
+	startTime := time.Now()
+
+	if rollbacker.dryRun {
+		log.Printf("Would revert %v %q with the following SQL:\n%s\n", migration.ID, migration.Name, target.downSQL)
+
+		return nil
+	}
+
+	log.Printf("Reverting %v %q, please wait...", migration.ID, migration.Name)
+
+	var unregisterErr error
+
+	unregister := func(ctx context.Context) error {
+		unregisterErr = rollbacker.unregisterMigration(ctx, migration.ID)
+
+		return unregisterErr
+	}
+
+	err := rollbacker.executeDownSQL(ctx, target, unregister)
+
+	rollbacker.history.record(ctx, rollbacker.connection, OperationRollback, migration.ID, migration.Name, startTime, err)
+
+	if unregisterErr != nil {
+		return wrapError(unregisterErr, ErrTypeUnregisterMigration)
+	}
+
+	if err != nil {
+		rollbackErr := &RollbackMigrationError{Cause: err, ID: migration.ID, Name: migration.Name}
+
+		return wrapError(rollbackErr, ErrTypeRollbackMigration)
+	}
+
+	if target.transaction != settings.TransactionModeAuto {
+		log.Printf("Unregistering %v %q in a separate transaction, because its transaction mode is %v",
+			migration.ID, migration.Name, target.transaction)
+
+		if err := rollbacker.unregisterMigration(ctx, migration.ID); err != nil {
+			return wrapError(err, ErrTypeUnregisterMigration)
+		}
+	}
+
+	durationStr := humanizeDuration(time.Since(startTime), "0ms")
+
+	log.Printf("Reverted  %v %q in %s", migration.ID, migration.Name, durationStr)
+
+	return nil
+}
+
+// executeDownSQL runs the down SQL with the session settings of the migration, which are restored afterwards.
+func (rollbacker *rollbacker) executeDownSQL(
+	ctx context.Context,
+	target rollbackTarget,
+	beforeCommit func(ctx context.Context) error,
+) error {
+	name := cmp.Or(target.dirName, target.migration.Name)
+	parameters := sessionParameters(target.session, applicationNamePrefix+name)
+
+	previous, err := applySession(ctx, rollbacker.connection, parameters)
+
+	defer func() {
+		if err := restoreSession(ctx, rollbacker.connection, previous); err != nil {
+			log.Println("Warning: cannot restore the session settings after the rollback:", err)
+		}
+	}()
+
+	if err != nil {
+		return err
+	}
+
+	timeoutCtx, cancel := withMigrationTimeout(ctx, target.session.Timeout)
+	defer cancel()
+
+	conn := rollbacker.connection.PgConn()
+	err = execMigration(timeoutCtx, conn, target.downSQL, target.transaction, target.execution, beforeCommit)
+
+	return timeoutError(ctx, timeoutCtx, target.session.Timeout, err)
+}
+
+func (rollbacker *rollbacker) unregisterMigration(ctx context.Context, id source.ID) error {
+	return rollbacker.migrationsRepo.Delete(ctx, rollbacker.connection, id)
+}
+
+func (rollbacker *rollbacker) acquireLock(ctx context.Context) error {
+	if rollbacker.dryRun {
+		return nil
+	}
+
+	return rollbacker.lock.Acquire(ctx, rollbacker.connection, rollbacker.lockTimeout)
+}
+
+func (rollbacker *rollbacker) releaseLock(ctx context.Context) {
+	if rollbacker.dryRun {
+		return
+	}
+
+	if err := rollbacker.lock.Release(ctx, rollbacker.connection); err != nil {
+		log.Println("Failed to release the migrations lock:", err)
+	}
+}
+
+func (rollbacker *rollbacker) connect(ctx context.Context) error {
+	connection, err := connect(ctx, rollbacker.databaseURL, rollbacker.searchPath)
+
+	rollbacker.connection = connection
+
+	if err != nil {
+		return err
+	}
+
+	// The connection holds the migrations lock.
+	return setApplicationName(ctx, connection, "rollback")
+}
+
+func (rollbacker *rollbacker) close(ctx context.Context) error {
+	return closeConnection(ctx, rollbacker.connection)
+}
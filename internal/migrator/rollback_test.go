@@ -0,0 +1,236 @@
+package migrator_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/servletcloud/Andmerada/internal/migrator"
+	"github.com/servletcloud/Andmerada/internal/project"
+	"github.com/servletcloud/Andmerada/internal/settings"
+	"github.com/servletcloud/Andmerada/internal/source"
+	"github.com/servletcloud/Andmerada/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:paralleltest,funlen
+func TestRollback(t *testing.T) {
+	connectionURL := tests.StartEmbeddedPostgres(t)
+	conn := tests.OpenPgConnection(t, connectionURL)
+	dir := t.TempDir()
+	testProject := project.Project{Dir: dir, Configuration: createProjectConfig(), Callbacks: nil}
+
+	applyOptions := newApplyOptions(connectionURL, dir)
+
+	rollbackOptions := migrator.RollbackOptions{
+		MaxSQLFileSize: 1024,
+		DatabaseURL:    string(connectionURL),
+		Project:        testProject,
+		Count:          1,
+		ToID:           source.EmptyMigrationID,
+		DownSQLSource:  migrator.DownSQLFromDatabase,
+		DryRun:         false,
+		LockTimeout:    migrator.DefaultLockTimeout,
+		Placeholders:   nil,
+	}
+
+	createMigration := func(t *testing.T, title, timestamp, upSQL, downSQL string) source.CreateSourceResult {
+		t.Helper()
+
+		result := tests.CreateSource(t, dir, title, timestamp)
+		writeUpSQL(t, result.FullPath, upSQL)
+		writeDownSQL(t, result.FullPath, downSQL)
+
+		return result
+	}
+
+	mustApplyPending := func(t *testing.T) {
+		t.Helper()
+
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+		require.NoError(t, migrator.ApplyPending(t.Context(), applyOptions, &report))
+	}
+
+	rollback := func(t *testing.T, options migrator.RollbackOptions) (migrator.RollbackReport, error) {
+		t.Helper()
+
+		report := migrator.RollbackReport{TargetCount: 0}
+		err := migrator.Rollback(t.Context(), options, &report)
+
+		return report, err
+	}
+
+	t.Run("Nothing to roll back when the migrations table does not exist", func(t *testing.T) {
+		report, err := rollback(t, rollbackOptions)
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, report.TargetCount)
+	})
+
+	createMigration(t, "Cr users", "20241225112129", "CREATE TABLE users (id INTEGER);", "DROP TABLE users;")
+	createMigration(t, "Cr orders", "20241228143752", "CREATE TABLE orders (id INTEGER);", "DROP TABLE orders;")
+	third := createMigration(t, "Cr items", "20241229143752", "CREATE TABLE items (id INTEGER);", "DROP TABLE items;")
+
+	mustApplyPending(t)
+
+	t.Run("In dry run mode, it does not roll back", func(t *testing.T) {
+		optionsCopy := rollbackOptions
+		optionsCopy.DryRun = true
+
+		report, err := rollback(t, optionsCopy)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.TargetCount)
+		tests.AssertPgTableExist(t, conn, "items")
+	})
+
+	t.Run("Rolls back the latest migration by default", func(t *testing.T) {
+		report, err := rollback(t, rollbackOptions)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.TargetCount)
+
+		tests.AssertPgTableNotExist(t, conn, "items")
+		tests.AssertPgTableExist(t, conn, "orders")
+		assertMigrationNotRegistered(t, conn, 20241229143752)
+
+		mustApplyPending(t)
+		tests.AssertPgTableExist(t, conn, "items")
+	})
+
+	t.Run("Fails when the target ID is not applied", func(t *testing.T) {
+		optionsCopy := rollbackOptions
+		optionsCopy.ToID = 20241225112130
+
+		_, err := rollback(t, optionsCopy)
+
+		var targetErr *migrator.RollbackTargetNotAppliedError
+
+		require.ErrorAs(t, err, &targetErr)
+		tests.AssertPgTableExist(t, conn, "items")
+	})
+
+	t.Run("Refuses to roll back a blocked migration", func(t *testing.T) {
+		_, err := conn.Exec(t.Context(), "UPDATE migrations SET rollback_blocked = TRUE WHERE id = 20241228143752")
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			_, err := conn.Exec(t.Context(), "UPDATE migrations SET rollback_blocked = FALSE")
+			require.NoError(t, err)
+		})
+
+		optionsCopy := rollbackOptions
+		optionsCopy.Count = 2
+
+		_, err = rollback(t, optionsCopy)
+
+		var blockedErr *migrator.RollbackBlockedError
+
+		require.ErrorAs(t, err, &blockedErr)
+		require.Len(t, blockedErr.Blocked, 1)
+		assert.Equal(t, source.ID(20241228143752), blockedErr.Blocked[0].ID)
+		assert.Equal(t, "This migration contains irreversible changes.", blockedErr.Blocked[0].Reason)
+
+		tests.AssertPgTableExist(t, conn, "items")
+		tests.AssertPgTableExist(t, conn, "orders")
+	})
+
+	t.Run("Uses the down SQL from disk when requested", func(t *testing.T) {
+		writeDownSQL(t, third.FullPath, "ALTER TABLE items RENAME TO items_disk;")
+
+		optionsCopy := rollbackOptions
+		optionsCopy.DownSQLSource = migrator.DownSQLFromDisk
+
+		_, err := rollback(t, optionsCopy)
+		require.NoError(t, err)
+
+		tests.AssertPgTableExist(t, conn, "items_disk")
+		assertMigrationNotRegistered(t, conn, 20241229143752)
+	})
+
+	t.Run("In the auto transaction mode, a failed unregistration reverts the down SQL", func(t *testing.T) {
+		_, err := conn.Exec(t.Context(), `
+			CREATE FUNCTION forbid_delete() RETURNS trigger LANGUAGE plpgsql AS $$
+			BEGIN RAISE EXCEPTION 'delete is forbidden'; END $$;
+			CREATE TRIGGER forbid_delete BEFORE DELETE ON migrations FOR EACH ROW EXECUTE FUNCTION forbid_delete();`)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			_, err := conn.Exec(t.Context(), "DROP TRIGGER forbid_delete ON migrations; DROP FUNCTION forbid_delete();")
+			require.NoError(t, err)
+		})
+
+		optionsCopy := rollbackOptions
+		optionsCopy.Project.Configuration.Transaction = settings.TransactionModeAuto
+
+		_, err = rollback(t, optionsCopy)
+
+		var migrateErr *migrator.MigrateError
+
+		require.ErrorAs(t, err, &migrateErr)
+		assert.Equal(t, migrator.ErrTypeUnregisterMigration, migrateErr.ErrType)
+
+		tests.AssertPgTableExist(t, conn, "orders")
+
+		var count int
+
+		require.NoError(t, conn.QueryRow(t.Context(), "SELECT COUNT(*) FROM migrations WHERE id = 20241228143752").Scan(&count))
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("Rolls back every migration after the target ID", func(t *testing.T) {
+		optionsCopy := rollbackOptions
+		optionsCopy.Count = migrator.NoLimit
+		optionsCopy.ToID = 20241225112129
+
+		report, err := rollback(t, optionsCopy)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.TargetCount)
+
+		tests.AssertPgTableNotExist(t, conn, "orders")
+		tests.AssertPgTableExist(t, conn, "users")
+	})
+
+	t.Run("Runs the down SQL with the session settings of the migration", func(t *testing.T) {
+		checkSession := "DO $$ BEGIN IF current_setting('application_name') <> 'andmerada:20250101101010_session' " +
+			"OR current_setting('lock_timeout') <> '7s' THEN " +
+			"RAISE EXCEPTION 'unexpected session: %', current_setting('application_name'); END IF; END $$;"
+
+		createMigration(t, "Session", "20250101101010", "SELECT 1;", checkSession)
+		mustApplyPending(t)
+
+		lockTimeout := 7 * time.Second
+
+		optionsCopy := rollbackOptions
+		optionsCopy.Project.Configuration.Session = settings.Session{LockTimeout: &lockTimeout} //nolint:exhaustruct
+
+		_, err := rollback(t, optionsCopy)
+		require.NoError(t, err)
+
+		assertMigrationNotRegistered(t, conn, 20250101101010)
+	})
+}
+
+func assertMigrationNotRegistered(t *testing.T, conn *pgx.Conn, id source.ID) {
+	t.Helper()
+
+	var count int
+
+	err := conn.QueryRow(t.Context(), "SELECT COUNT(*) FROM migrations WHERE id = $1", id).Scan(&count)
+	require.NoError(t, err)
+
+	assert.Zero(t, count, "Migration %v is registered", id)
+}
+
+func writeDownSQL(t *testing.T, dir string, content string) {
+	t.Helper()
+
+	path := filepath.Join(dir, source.DownSQLFilename)
+	err := os.WriteFile(path, []byte(content), 0600)
+
+	require.NoError(t, err)
+}
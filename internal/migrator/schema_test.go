@@ -0,0 +1,118 @@
+package migrator_test
+
+import (
+	"testing"
+
+	"github.com/servletcloud/Andmerada/internal/migrator"
+	"github.com/servletcloud/Andmerada/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:paralleltest
+func TestApplyPendingSchemaQualifiedTable(t *testing.T) {
+	connectionURL := tests.StartEmbeddedPostgres(t)
+	conn := tests.OpenPgConnection(t, connectionURL)
+	dir := t.TempDir()
+
+	_, err := conn.Exec(t.Context(), "CREATE SCHEMA app;")
+	require.NoError(t, err)
+
+	options := newApplyOptions(connectionURL, dir)
+	options.Project.Configuration.MigrationsTableName = "ops.migrations"
+	options.Project.Configuration.CreateSchema = true
+	options.Project.Configuration.SearchPath = []string{"app"}
+
+	result := tests.CreateSource(t, dir, "Create accounts", "20250901101010")
+	writeUpSQL(t, result.FullPath, "CREATE TABLE accounts (id INTEGER);")
+
+	report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+	require.NoError(t, migrator.ApplyPending(t.Context(), options, &report))
+
+	var count int
+
+	row := conn.QueryRow(t.Context(), "SELECT COUNT(*) FROM ops.migrations WHERE id = 20250901101010")
+	require.NoError(t, row.Scan(&count))
+	assert.Equal(t, 1, count)
+
+	row = conn.QueryRow(t.Context(), "SELECT COUNT(*) FROM ops.migrations_history")
+	require.NoError(t, row.Scan(&count))
+	assert.Equal(t, 1, count)
+
+	row = conn.QueryRow(t.Context(),
+		"SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = 'app' AND table_name = 'accounts'")
+	require.NoError(t, row.Scan(&count))
+	assert.Equal(t, 1, count, "the migration runs with the configured search_path")
+
+	require.NoError(t, migrator.ApplyPending(t.Context(), options, &report))
+	assert.Equal(t, 0, report.PendingCount)
+}
+
+//nolint:paralleltest,funlen
+func TestSearchPathResolvesUnqualifiedTable(t *testing.T) {
+	connectionURL := tests.StartEmbeddedPostgres(t)
+	conn := tests.OpenPgConnection(t, connectionURL)
+	dir := t.TempDir()
+
+	_, err := conn.Exec(t.Context(), "CREATE SCHEMA app;")
+	require.NoError(t, err)
+
+	options := newApplyOptions(connectionURL, dir)
+	options.Project.Configuration.SearchPath = []string{"app"}
+	testProject := options.Project
+
+	first := tests.CreateSource(t, dir, "First", "20250902101010")
+	writeUpSQL(t, first.FullPath, "CREATE TABLE first (id INTEGER);")
+
+	report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+	require.NoError(t, migrator.ApplyPending(t.Context(), options, &report))
+	tests.AssertPgTableNotExist(t, conn, "migrations")
+
+	second := tests.CreateSource(t, dir, "Second", "20250902101011")
+	writeUpSQL(t, second.FullPath, "CREATE TABLE second (id INTEGER);")
+
+	t.Run("status reads the table of the search_path", func(t *testing.T) {
+		statusOptions := migrator.StatusOptions{
+			MaxSQLFileSize: 1024,
+			DatabaseURL:    string(connectionURL),
+			Project:        testProject,
+		}
+		statusReport := migrator.StatusReport{Entries: nil}
+
+		require.NoError(t, migrator.Status(t.Context(), statusOptions, &statusReport))
+		assert.Equal(t, 1, statusReport.CountOf(migrator.StateApplied))
+		assert.Equal(t, 1, statusReport.CountOf(migrator.StatePending))
+	})
+
+	t.Run("verify reads the table of the search_path", func(t *testing.T) {
+		verifyOptions := migrator.VerifyOptions{
+			MaxSQLFileSize: 1024,
+			DatabaseURL:    string(connectionURL),
+			Project:        testProject,
+			AllowDrift:     false,
+		}
+		verifyReport := migrator.VerifyReport{AppliedCount: 0, Drifts: nil}
+
+		require.NoError(t, migrator.Verify(t.Context(), verifyOptions, &verifyReport))
+		assert.Equal(t, 1, verifyReport.AppliedCount)
+	})
+
+	t.Run("mark-applied writes to the table of the search_path", func(t *testing.T) {
+		baselineOptions := migrator.BaselineOptions{
+			MaxSQLFileSize: 1024,
+			DatabaseURL:    string(connectionURL),
+			Project:        testProject,
+			ID:             20250902101011,
+			LockTimeout:    migrator.DefaultLockTimeout,
+		}
+		baselineReport := migrator.BaselineReport{MarkedIDs: nil}
+
+		require.NoError(t, migrator.MarkApplied(t.Context(), baselineOptions, &baselineReport))
+
+		var count int
+
+		require.NoError(t, conn.QueryRow(t.Context(), "SELECT COUNT(*) FROM app.migrations").Scan(&count))
+		assert.Equal(t, 2, count)
+		tests.AssertPgTableNotExist(t, conn, "migrations")
+	})
+}
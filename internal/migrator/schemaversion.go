@@ -0,0 +1,120 @@
+package migrator
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/servletcloud/Andmerada/internal/migrator/sqlres"
+	"github.com/servletcloud/Andmerada/internal/project"
+	"github.com/servletcloud/Andmerada/internal/settings"
+)
+
+const (
+	// legacySchemaVersion is the version of installations that only have the migrations table.
+	legacySchemaVersion = 1
+)
+
+type SelfUpgradeOptions struct {
+	DatabaseURL string
+	Project     project.Project
+	LockTimeout time.Duration
+	DryRun      bool
+}
+
+type SelfUpgradeReport struct {
+	FromVersion int
+	ToVersion   int
+}
+
+// SelfUpgrade brings Andmerada's own bookkeeping tables to the version that this binary maintains.
+func SelfUpgrade(ctx context.Context, options SelfUpgradeOptions, report *SelfUpgradeReport) error {
+	configuration := options.Project.Configuration
+
+	report.FromVersion = 0
+	report.ToVersion = sqlres.LatestSchemaVersion()
+
+	connection, err := connect(ctx, options.DatabaseURL, configuration.SearchPath)
+
+	defer closeConnection(ctx, connection) //nolint:errcheck
+
+	if err != nil {
+		return wrapError(err, ErrTypeDBConnect)
+	}
+
+	lock := &Lock{TableName: configuration.MigrationsTableName}
+
+	if err := lock.Acquire(ctx, connection, options.LockTimeout); err != nil {
+		return wrapError(err, ErrTypeAcquireLock)
+	}
+
+	defer func() {
+		if err := lock.Release(ctx, connection); err != nil {
+			log.Println("Failed to release the migrations lock:", err)
+		}
+	}()
+
+	repo := &Migrations{TableName: configuration.MigrationsTableName, CreateSchema: configuration.CreateSchema}
+
+	version, err := checkSchemaVersion(ctx, connection, repo)
+	if err != nil {
+		return err
+	}
+
+	report.FromVersion = version
+
+	if version == report.ToVersion || options.DryRun {
+		return nil
+	}
+
+	if err := repo.Upgrade(ctx, connection, version); err != nil {
+		return wrapError(err, ErrTypeCreateDDL)
+	}
+
+	return nil
+}
+
+// checkSchemaVersion returns the version of the bookkeeping schema and refuses a schema that is
+// newer than this binary maintains, because an older binary does not know how to keep it consistent.
+func checkSchemaVersion(ctx context.Context, conn *pgx.Conn, repo *Migrations) (int, error) {
+	version, err := repo.SchemaVersion(ctx, conn)
+	if err != nil {
+		return 0, wrapError(err, ErrTypeScanAppliedMigrations)
+	}
+
+	if latest := sqlres.LatestSchemaVersion(); version > latest {
+		return 0, wrapError(&SchemaTooNewError{Version: version, Supported: latest}, ErrTypeSchemaVersion)
+	}
+
+	return version, nil
+}
+
+// prepareSchema creates the bookkeeping schema in a fresh database, or upgrades an existing one
+// according to the self_upgrade mode.
+func prepareSchema(ctx context.Context, conn *pgx.Conn, repo *Migrations, mode settings.SelfUpgradeMode) error {
+	version, err := checkSchemaVersion(ctx, conn, repo)
+	if err != nil {
+		return err
+	}
+
+	latest := sqlres.LatestSchemaVersion()
+
+	if version == latest {
+		return nil
+	}
+
+	if version > 0 {
+		if settings.ResolveSelfUpgradeMode(mode) == settings.SelfUpgradeManual {
+			return wrapError(&SchemaUpgradeRequiredError{Version: version, Latest: latest}, ErrTypeSchemaVersion)
+		}
+
+		log.Printf("Upgrading the bookkeeping schema from version %d to %d...", version, latest)
+	}
+
+	if err := repo.Upgrade(ctx, conn, version); err != nil {
+		return wrapError(err, ErrTypeCreateDDL)
+	}
+
+	return nil
+}
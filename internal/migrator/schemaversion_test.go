@@ -0,0 +1,95 @@
+package migrator_test
+
+import (
+	"testing"
+
+	"github.com/servletcloud/Andmerada/internal/migrator"
+	"github.com/servletcloud/Andmerada/internal/migrator/sqlres"
+	"github.com/servletcloud/Andmerada/internal/project"
+	"github.com/servletcloud/Andmerada/internal/settings"
+	"github.com/servletcloud/Andmerada/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:paralleltest,funlen
+func TestBookkeepingSchemaVersion(t *testing.T) {
+	connectionURL := tests.StartEmbeddedPostgres(t)
+	conn := tests.OpenPgConnection(t, connectionURL)
+	dir := t.TempDir()
+
+	configuration := createProjectConfig()
+	configuration.SelfUpgrade = settings.SelfUpgradeManual
+	testProject := project.Project{Dir: dir, Configuration: configuration, Callbacks: nil}
+
+	applyOptions := newApplyOptions(connectionURL, dir)
+	applyOptions.Project = testProject
+
+	selfUpgradeOptions := migrator.SelfUpgradeOptions{
+		DatabaseURL: string(connectionURL),
+		Project:     testProject,
+		LockTimeout: migrator.DefaultLockTimeout,
+		DryRun:      false,
+	}
+
+	applyPending := func(t *testing.T) error {
+		t.Helper()
+
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+
+		return migrator.ApplyPending(t.Context(), applyOptions, &report)
+	}
+
+	tests.CreateSource(t, dir, "Create accounts", "20251001101010")
+
+	// The migrations table as it was created before the bookkeeping schema was versioned.
+	_, err := conn.Exec(t.Context(), `
+		CREATE TABLE migrations (
+			id BIGINT PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW (),
+			sql_up TEXT NOT NULL,
+			sql_down TEXT,
+			sql_up_sha256 TEXT NOT NULL,
+			sql_down_sha256 TEXT,
+			duration_ms BIGINT NOT NULL,
+			rollback_blocked BOOLEAN NOT NULL DEFAULT FALSE,
+			meta JSONB NOT NULL
+		);`)
+	require.NoError(t, err)
+
+	t.Run("A legacy installation requires self-upgrade in manual mode", func(t *testing.T) {
+		var upgradeErr *migrator.SchemaUpgradeRequiredError
+
+		require.ErrorAs(t, applyPending(t), &upgradeErr)
+		assert.Equal(t, 1, upgradeErr.Version)
+		assert.Equal(t, sqlres.LatestSchemaVersion(), upgradeErr.Latest)
+	})
+
+	t.Run("Self-upgrade applies the missing steps", func(t *testing.T) {
+		report := migrator.SelfUpgradeReport{FromVersion: 0, ToVersion: 0}
+
+		require.NoError(t, migrator.SelfUpgrade(t.Context(), selfUpgradeOptions, &report))
+		assert.Equal(t, 1, report.FromVersion)
+		assert.Equal(t, sqlres.LatestSchemaVersion(), report.ToVersion)
+
+		tests.AssertPgTableExist(t, conn, "migrations_history")
+		require.NoError(t, applyPending(t))
+
+		require.NoError(t, migrator.SelfUpgrade(t.Context(), selfUpgradeOptions, &report))
+		assert.Equal(t, report.ToVersion, report.FromVersion)
+	})
+
+	t.Run("A newer bookkeeping schema is refused", func(t *testing.T) {
+		_, err := conn.Exec(t.Context(), "INSERT INTO migrations_schema_version (version) VALUES (999);")
+		require.NoError(t, err)
+
+		var tooNewErr *migrator.SchemaTooNewError
+
+		require.ErrorAs(t, applyPending(t), &tooNewErr)
+		assert.Equal(t, 999, tooNewErr.Version)
+
+		statusOptions := migrator.StatusOptions{MaxSQLFileSize: 1024, DatabaseURL: string(connectionURL), Project: testProject}
+		require.ErrorAs(t, migrator.Status(t.Context(), statusOptions, &migrator.StatusReport{Entries: nil}), &tooNewErr)
+	})
+}
@@ -0,0 +1,132 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/servletcloud/Andmerada/internal/settings"
+)
+
+// applicationNamePrefix tags the session of a migration in pg_stat_activity, e.g. andmerada:20241225112129_add_users.
+const applicationNamePrefix = "andmerada:"
+
+// sessionParameter is a run-time parameter of PostgreSQL, see set_config.
+type sessionParameter struct {
+	name  string
+	value string
+}
+
+// sessionParameters returns the parameters to set for a migration. Timeouts are set in milliseconds.
+func sessionParameters(session settings.Session, applicationName string) []sessionParameter {
+	parameters := []sessionParameter{{name: "application_name", value: applicationName}}
+
+	addTimeout := func(name string, timeout *time.Duration) {
+		if timeout != nil {
+			parameters = append(parameters, sessionParameter{name: name, value: strconv.FormatInt(timeout.Milliseconds(), 10)})
+		}
+	}
+
+	addTimeout("lock_timeout", session.LockTimeout)
+	addTimeout("statement_timeout", session.StatementTimeout)
+	addTimeout("idle_in_transaction_session_timeout", session.IdleInTransactionSessionTimeout)
+
+	if session.WorkMem != "" {
+		parameters = append(parameters, sessionParameter{name: "work_mem", value: session.WorkMem})
+	}
+
+	if len(session.SearchPath) > 0 {
+		sanitized := make([]string, 0, len(session.SearchPath))
+
+		for _, schema := range session.SearchPath {
+			sanitized = append(sanitized, pgx.Identifier{schema}.Sanitize())
+		}
+
+		parameters = append(parameters, sessionParameter{name: "search_path", value: strings.Join(sanitized, ", ")})
+	}
+
+	return parameters
+}
+
+// applySession sets the parameters on the session and returns their previous values for restoreSession.
+func applySession(ctx context.Context, conn *pgx.Conn, parameters []sessionParameter) ([]sessionParameter, error) {
+	previous := make([]sessionParameter, 0, len(parameters))
+
+	for _, parameter := range parameters {
+		var value string
+
+		query := "SELECT current_setting($1)"
+
+		if err := conn.QueryRow(ctx, query, parameter.name).Scan(&value); err != nil {
+			return previous, &ExecSQLError{Cause: err, SQL: query}
+		}
+
+		previous = append(previous, sessionParameter{name: parameter.name, value: value})
+
+		if err := setSessionParameter(ctx, conn, parameter); err != nil {
+			return previous, err
+		}
+	}
+
+	return previous, nil
+}
+
+// restoreSession sets the parameters back to the values returned by applySession. A failed migration may leave
+// an aborted transaction behind, which is rolled back first. A closed connection has nothing to restore.
+func restoreSession(ctx context.Context, conn *pgx.Conn, previous []sessionParameter) error {
+	if conn.IsClosed() {
+		return nil
+	}
+
+	if isConnectionInTransaction(conn.PgConn()) {
+		if err := execSimple(ctx, conn.PgConn(), "ROLLBACK;"); err != nil {
+			return &ExecSQLError{Cause: err, SQL: "ROLLBACK;"}
+		}
+	}
+
+	for _, parameter := range previous {
+		if err := setSessionParameter(ctx, conn, parameter); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func setSessionParameter(ctx context.Context, conn *pgx.Conn, parameter sessionParameter) error {
+	query := "SELECT set_config($1, $2, false)"
+
+	if _, err := conn.Exec(ctx, query, parameter.name, parameter.value); err != nil {
+		return &ExecSQLError{Cause: err, SQL: query}
+	}
+
+	return nil
+}
+
+// setApplicationName tags the session in pg_stat_activity, e.g. andmerada:migrate for the holder of the migrations
+// lock, so that LockTimeoutError and 'andmerada lock status' can tell who holds it.
+func setApplicationName(ctx context.Context, conn *pgx.Conn, name string) error {
+	return setSessionParameter(ctx, conn, sessionParameter{name: "application_name", value: applicationNamePrefix + name})
+}
+
+// withMigrationTimeout bounds the context by the wall-clock timeout of the session, if any.
+func withMigrationTimeout(ctx context.Context, timeout *time.Duration) (context.Context, context.CancelFunc) {
+	if timeout == nil || *timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, *timeout)
+}
+
+// timeoutError returns MigrationTimeoutError when err is caused by the deadline of timeoutCtx
+// rather than by the cancellation of its parent.
+func timeoutError(ctx context.Context, timeoutCtx context.Context, timeout *time.Duration, err error) error {
+	if err == nil || ctx.Err() != nil || !errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+
+	return &MigrationTimeoutError{Cause: err, Timeout: *timeout}
+}
@@ -0,0 +1,110 @@
+package migrator_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/servletcloud/Andmerada/internal/migrator"
+	"github.com/servletcloud/Andmerada/internal/settings"
+	"github.com/servletcloud/Andmerada/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:paralleltest,funlen
+func TestSessionSettings(t *testing.T) {
+	connectionURL := tests.StartEmbeddedPostgres(t)
+	conn := tests.OpenPgConnection(t, connectionURL)
+
+	lockTimeout := 10 * time.Second
+
+	newOptions := func(dir string) migrator.ApplyOptions {
+		options := newApplyOptions(connectionURL, dir)
+		options.Project.Configuration.Session = settings.Session{ //nolint:exhaustruct
+			LockTimeout: &lockTimeout,
+			WorkMem:     "8MB",
+		}
+		options.AllowDrift = true
+
+		return options
+	}
+
+	_, err := conn.Exec(t.Context(), "CREATE TABLE session_log (seq SERIAL, settings TEXT);")
+	require.NoError(t, err)
+
+	logSettings := "INSERT INTO session_log (settings) VALUES (concat_ws(' ', " +
+		"current_setting('application_name'), current_setting('lock_timeout'), " +
+		"current_setting('statement_timeout'), current_setting('work_mem')));"
+
+	t.Run("Applies the session settings to each migration and restores them", func(t *testing.T) {
+		dir := t.TempDir()
+
+		first := tests.CreateSource(t, dir, "First", "20250601101010")
+		writeUpSQL(t, first.FullPath, logSettings)
+		appendToMigrationYml(t, first.FullPath, "session:\n  statement_timeout: 5m\n  work_mem: 16MB\n")
+
+		second := tests.CreateSource(t, dir, "Second", "20250602101010")
+		writeUpSQL(t, second.FullPath, logSettings)
+
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+		require.NoError(t, migrator.ApplyPending(t.Context(), newOptions(dir), &report))
+
+		rows, err := conn.Query(t.Context(), "SELECT settings FROM session_log ORDER BY seq")
+		require.NoError(t, err)
+
+		logged, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		require.NoError(t, err)
+
+		expected := []string{
+			"andmerada:20250601101010_first 10s 5min 16MB",
+			"andmerada:20250602101010_second 10s 0 8MB",
+		}
+		assert.Equal(t, expected, logged)
+	})
+
+	t.Run("Fails a migration that exceeds the timeout", func(t *testing.T) {
+		dir := t.TempDir()
+
+		slow := tests.CreateSource(t, dir, "Slow", "20250603101010")
+		writeUpSQL(t, slow.FullPath, "SELECT pg_sleep(10);")
+		appendToMigrationYml(t, slow.FullPath, "session:\n  timeout: 200ms\n")
+
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+		err := migrator.ApplyPending(t.Context(), newOptions(dir), &report)
+
+		var timeoutErr *migrator.MigrationTimeoutError
+
+		require.ErrorAs(t, err, &timeoutErr)
+		assert.Equal(t, 200*time.Millisecond, timeoutErr.Timeout)
+		assert.Equal(t, migrator.EntryFailed, report.Entries[0].Status)
+	})
+
+	t.Run("Rehearses each migration with its own session settings", func(t *testing.T) {
+		dir := t.TempDir()
+
+		// A rehearsal is rolled back, so the migrations raise on unexpected settings instead of logging them.
+		expectSettings := func(applicationName, statementTimeout string) string {
+			return "DO $$ BEGIN IF current_setting('application_name') <> '" + applicationName + "' " +
+				"OR current_setting('statement_timeout') <> '" + statementTimeout + "' THEN " +
+				"RAISE EXCEPTION 'unexpected session: %', current_setting('statement_timeout'); END IF; END $$;"
+		}
+
+		first := tests.CreateSource(t, dir, "First", "20250604101010")
+		writeUpSQL(t, first.FullPath, expectSettings("andmerada:20250604101010_first", "5min"))
+		appendToMigrationYml(t, first.FullPath, "session:\n  statement_timeout: 5m\n")
+
+		second := tests.CreateSource(t, dir, "Second", "20250605101010")
+		writeUpSQL(t, second.FullPath, expectSettings("andmerada:20250605101010_second", "0"))
+
+		options := newOptions(dir)
+		options.Rehearse = true
+
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+		require.NoError(t, migrator.ApplyPending(t.Context(), options, &report))
+
+		require.Len(t, report.Entries, 2)
+		assert.Equal(t, migrator.EntryRehearsed, report.Entries[0].Status)
+		assert.Equal(t, migrator.EntryRehearsed, report.Entries[1].Status)
+	})
+}
@@ -0,0 +1,4 @@
+GRANT SELECT, INSERT, UPDATE, DELETE ON TABLE _table_ TO _role_;
+GRANT SELECT, INSERT ON TABLE _history_table_ TO _role_;
+GRANT USAGE ON SEQUENCE _history_sequence_ TO _role_;
+GRANT SELECT ON TABLE _schema_version_table_ TO _role_;
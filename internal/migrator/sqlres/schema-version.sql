@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS _schema_version_table_ (
+    version INTEGER PRIMARY KEY, -- The version of Andmerada's own tables, one row per applied upgrade step
+    upgraded_at TIMESTAMPTZ NOT NULL DEFAULT NOW ()
+);
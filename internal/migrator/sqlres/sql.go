@@ -2,19 +2,179 @@ package sqlres
 
 import (
 	_ "embed"
+	"fmt"
 	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	historyTableSuffix       = "_history"
+	historyIndexSuffix       = "_history_migration_id_idx"
+	historySequenceSuffix    = "_history_attempt_id_seq"
+	schemaVersionTableSuffix = "_schema_version"
 )
 
-//go:embed ddl.sql
-var ddl string
+//go:embed schema-version.sql
+var schemaVersionDDL string
+
+//go:embed upgrades/001-migrations-table.sql
+var upgrade001 string
+
+//go:embed upgrades/002-history-table.sql
+var upgrade002 string
+
+// upgrades are the steps that create and evolve Andmerada's own tables. The version of a step is
+// its index plus one. Steps are append-only: a released step must never change, because existing
+// installations have already applied it.
+var upgrades = []string{
+	upgrade001,
+	upgrade002,
+}
 
 //go:embed register-migration.sql
 var registerMigrationQuery string
 
+// registerRepeatableQuery re-records a repeatable migration that is applied again. Versioned migrations are
+// registered with registerMigrationQuery, so registering one twice fails on the primary key.
+//
+//go:embed register-repeatable.sql
+var registerRepeatableQuery string
+
+//go:embed grants.sql
+var grants string
+
+// Table is the name of a table, optionally qualified with a schema, e.g. "ops.migrations".
+type Table struct {
+	Schema string
+	Name   string
+}
+
+// ParseTable splits a migrations_table_name value into the schema and the table name.
+func ParseTable(name string) Table {
+	schema, table, found := strings.Cut(name, ".")
+	if !found {
+		return Table{Schema: "", Name: name}
+	}
+
+	return Table{Schema: schema, Name: table}
+}
+
+func (t Table) WithSuffix(suffix string) Table {
+	return Table{Schema: t.Schema, Name: t.Name + suffix}
+}
+
+// Sanitize returns the quoted identifier that is safe to embed in SQL.
+func (t Table) Sanitize() string {
+	if t.Schema == "" {
+		return pgx.Identifier{t.Name}.Sanitize()
+	}
+
+	return pgx.Identifier{t.Schema, t.Name}.Sanitize()
+}
+
+func HistoryTable(tableName string) Table {
+	return ParseTable(tableName).WithSuffix(historyTableSuffix)
+}
+
+// upgrade is one step of the bookkeeping schema, which brings it to version.
+type upgrade struct {
+	version int
+	sql     string
+}
+
+// LatestSchemaVersion is the version of the bookkeeping schema that this build of Andmerada maintains.
+func LatestSchemaVersion() int {
+	return len(upgrades)
+}
+
+func SchemaVersionTable(tableName string) Table {
+	return ParseTable(tableName).WithSuffix(schemaVersionTableSuffix)
+}
+
+// UpgradeSQL returns the SQL that brings the bookkeeping schema from fromVersion to LatestSchemaVersion,
+// including the creation of the schema version table and the records of the applied steps.
+func UpgradeSQL(tableName string, fromVersion int) string {
+	replacer := tableReplacer(ParseTable(tableName))
+
+	var sb strings.Builder
+
+	sb.WriteString(replacer.Replace(schemaVersionDDL))
+
+	for _, upgrade := range pendingUpgrades(tableName, fromVersion) {
+		sb.WriteString("\n")
+		sb.WriteString(upgrade.sql)
+		sb.WriteString("\n")
+		sb.WriteString(replacer.Replace(fmt.Sprintf(
+			"INSERT INTO _schema_version_table_ (version) VALUES (%d) ON CONFLICT (version) DO NOTHING;\n",
+			upgrade.version,
+		)))
+	}
+
+	return sb.String()
+}
+
+// pendingUpgrades returns the steps that follow fromVersion, in the order they must be applied.
+func pendingUpgrades(tableName string, fromVersion int) []upgrade {
+	replacer := tableReplacer(ParseTable(tableName))
+
+	var result []upgrade
+
+	for i, sql := range upgrades {
+		if version := i + 1; version > fromVersion {
+			result = append(result, upgrade{version: version, sql: replacer.Replace(sql)})
+		}
+	}
+
+	return result
+}
+
+// DDL returns the SQL that creates the bookkeeping schema from scratch at LatestSchemaVersion.
 func DDL(tableName string) string {
-	return strings.ReplaceAll(ddl, "_table_name_", tableName)
+	return UpgradeSQL(tableName, 0)
+}
+
+// SchemaDDL returns the statement that creates the schema of the table, or an empty string
+// when the table name is not qualified with a schema.
+func SchemaDDL(tableName string) string {
+	table := ParseTable(tableName)
+
+	if table.Schema == "" {
+		return ""
+	}
+
+	return "CREATE SCHEMA IF NOT EXISTS " + pgx.Identifier{table.Schema}.Sanitize() + ";\n\n"
 }
 
 func RegisterMigrationQuery(tableName string) string {
-	return strings.ReplaceAll(registerMigrationQuery, "_table_name_", tableName)
+	return tableReplacer(ParseTable(tableName)).Replace(registerMigrationQuery)
+}
+
+func RegisterRepeatableQuery(tableName string) string {
+	return tableReplacer(ParseTable(tableName)).Replace(registerRepeatableQuery)
+}
+
+func Grants(tableName, role string) string {
+	table := ParseTable(tableName)
+	sanitizedRole := pgx.Identifier{role}.Sanitize()
+
+	result := strings.ReplaceAll(tableReplacer(table).Replace(grants), "_role_", sanitizedRole)
+
+	if table.Schema == "" {
+		return result
+	}
+
+	schemaGrant := "GRANT USAGE ON SCHEMA " + pgx.Identifier{table.Schema}.Sanitize() + " TO " + sanitizedRole + ";\n"
+
+	return schemaGrant + result
+}
+
+func tableReplacer(table Table) *strings.Replacer {
+	return strings.NewReplacer(
+		"_table_", table.Sanitize(),
+		"_history_table_", table.WithSuffix(historyTableSuffix).Sanitize(),
+		"_history_index_", pgx.Identifier{table.Name + historyIndexSuffix}.Sanitize(),
+		"_history_sequence_", table.WithSuffix(historySequenceSuffix).Sanitize(),
+		"_schema_version_table_", table.WithSuffix(schemaVersionTableSuffix).Sanitize(),
+	)
 }
@@ -0,0 +1,121 @@
+package sqlres_test
+
+import (
+	"testing"
+
+	"github.com/servletcloud/Andmerada/internal/migrator/sqlres"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGrants(t *testing.T) {
+	t.Parallel()
+
+	t.Run("grants privileges on the migrations table", func(t *testing.T) {
+		t.Parallel()
+
+		actual := sqlres.Grants("migrations", "app_migrator")
+
+		assert.Contains(t, actual, `ON TABLE "migrations" TO "app_migrator";`)
+		assert.Contains(t, actual, "SELECT, INSERT, UPDATE, DELETE")
+	})
+
+	t.Run("grants privileges on the history table", func(t *testing.T) {
+		t.Parallel()
+
+		actual := sqlres.Grants("migrations", "app_migrator")
+
+		assert.Contains(t, actual, `GRANT SELECT, INSERT ON TABLE "migrations_history" TO "app_migrator";`)
+		assert.Contains(t, actual, `ON SEQUENCE "migrations_history_attempt_id_seq" TO "app_migrator";`)
+		assert.Contains(t, actual, `GRANT SELECT ON TABLE "migrations_schema_version" TO "app_migrator";`)
+	})
+
+	t.Run("grants usage on the schema of the migrations table", func(t *testing.T) {
+		t.Parallel()
+
+		actual := sqlres.Grants("ops.migrations", "app_migrator")
+
+		assert.Contains(t, actual, `GRANT USAGE ON SCHEMA "ops" TO "app_migrator";`)
+		assert.Contains(t, actual, `ON TABLE "ops"."migrations" TO "app_migrator";`)
+		assert.NotContains(t, sqlres.Grants("migrations", "app_migrator"), "ON SCHEMA")
+	})
+
+	t.Run("quotes the role name", func(t *testing.T) {
+		t.Parallel()
+
+		actual := sqlres.Grants("migrations", `evil"; DROP TABLE users; --`)
+
+		assert.Contains(t, actual, `TO "evil""; DROP TABLE users; --";`)
+	})
+}
+
+func TestParseTable(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, sqlres.Table{Schema: "", Name: "migrations"}, sqlres.ParseTable("migrations"))
+	assert.Equal(t, sqlres.Table{Schema: "ops", Name: "migrations"}, sqlres.ParseTable("ops.migrations"))
+	assert.Equal(t, `"ops"."migrations"`, sqlres.ParseTable("ops.migrations").Sanitize())
+	assert.Equal(t, `"ops"."migrations_history"`, sqlres.HistoryTable("ops.migrations").Sanitize())
+}
+
+func TestDDL(t *testing.T) {
+	t.Parallel()
+
+	t.Run("qualifies the tables with the schema", func(t *testing.T) {
+		t.Parallel()
+
+		actual := sqlres.DDL("ops.migrations")
+
+		assert.Contains(t, actual, `CREATE TABLE IF NOT EXISTS "ops"."migrations" (`)
+		assert.Contains(t, actual, `CREATE TABLE IF NOT EXISTS "ops"."migrations_history" (`)
+		assert.Contains(t, actual, `"migrations_history_migration_id_idx" ON "ops"."migrations_history"`)
+	})
+
+	t.Run("quotes the table name", func(t *testing.T) {
+		t.Parallel()
+
+		actual := sqlres.DDL(`evil"; DROP TABLE users; --`)
+
+		assert.Contains(t, actual, `CREATE TABLE IF NOT EXISTS "evil""; DROP TABLE users; --" (`)
+	})
+}
+
+func TestSchemaDDL(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, sqlres.SchemaDDL("migrations"))
+	assert.Contains(t, sqlres.SchemaDDL("ops.migrations"), `CREATE SCHEMA IF NOT EXISTS "ops";`)
+}
+
+func TestUpgradeSQL(t *testing.T) {
+	t.Parallel()
+
+	t.Run("creates everything from scratch", func(t *testing.T) {
+		t.Parallel()
+
+		actual := sqlres.UpgradeSQL("migrations", 0)
+
+		assert.Contains(t, actual, `CREATE TABLE IF NOT EXISTS "migrations_schema_version" (`)
+		assert.Contains(t, actual, `CREATE TABLE IF NOT EXISTS "migrations" (`)
+		assert.Contains(t, actual, `INSERT INTO "migrations_schema_version" (version) VALUES (1)`)
+		assert.Contains(t, actual, `VALUES (2)`)
+		assert.Equal(t, sqlres.DDL("migrations"), actual)
+	})
+
+	t.Run("applies only the steps after the given version", func(t *testing.T) {
+		t.Parallel()
+
+		actual := sqlres.UpgradeSQL("migrations", 1)
+
+		assert.NotContains(t, actual, `CREATE TABLE IF NOT EXISTS "migrations" (`)
+		assert.NotContains(t, actual, `VALUES (1)`)
+		assert.Contains(t, actual, `CREATE TABLE IF NOT EXISTS "migrations_history" (`)
+	})
+
+	t.Run("creates only the version table when up to date", func(t *testing.T) {
+		t.Parallel()
+
+		actual := sqlres.UpgradeSQL("migrations", sqlres.LatestSchemaVersion())
+
+		assert.NotContains(t, actual, "INSERT INTO")
+	})
+}
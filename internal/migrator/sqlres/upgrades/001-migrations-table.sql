@@ -1,4 +1,4 @@
-CREATE TABLE IF NOT EXISTS "_table_name_" (
+CREATE TABLE IF NOT EXISTS _table_ (
     id BIGINT PRIMARY KEY, -- The unique migration ID (e.g., a timestamp like 20241225112129)
     name TEXT NOT NULL CHECK (char_length(name) <= 255),
     applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW (),
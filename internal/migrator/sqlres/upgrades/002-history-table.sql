@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS _history_table_ (
+    attempt_id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    migration_id BIGINT NOT NULL,
+    name TEXT NOT NULL CHECK (char_length(name) <= 255),
+    operation TEXT NOT NULL CHECK (operation IN ('apply', 'rollback')),
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL,
+    outcome TEXT NOT NULL CHECK (outcome IN ('succeeded', 'failed')),
+    sqlstate TEXT,
+    message TEXT,
+    andmerada_version TEXT NOT NULL,
+    db_user TEXT NOT NULL DEFAULT current_user,
+    client_hostname TEXT,
+    git_commit TEXT
+);
+
+CREATE INDEX IF NOT EXISTS _history_index_ ON _history_table_ (migration_id);
@@ -3,10 +3,64 @@ package migrator
 import (
 	"context"
 	"errors"
+	"log"
+	"strings"
 
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 )
 
+// connect opens a connection with the search_path of andmerada.yml, so that an unqualified
+// migrations_table_name resolves to the same table in every command.
+func connect(ctx context.Context, databaseURL string, searchPath []string) (*pgx.Conn, error) {
+	return connectWithNotices(ctx, databaseURL, searchPath, nil)
+}
+
+// connectWithNotices connects like connect and passes the notices of the connection to the handler.
+func connectWithNotices(
+	ctx context.Context,
+	databaseURL string,
+	searchPath []string,
+	handler pgconn.NoticeHandler,
+) (*pgx.Conn, error) {
+	config, err := pgx.ParseConfig(databaseURL)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	if handler != nil {
+		config.OnNotice = handler
+	}
+
+	connection, err := pgx.ConnectConfig(ctx, config)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	if err := setSearchPath(ctx, connection, searchPath); err != nil {
+		_ = closeConnection(ctx, connection)
+
+		return nil, err
+	}
+
+	return connection, nil
+}
+
+func closeConnection(ctx context.Context, conn *pgx.Conn) error {
+	if conn == nil {
+		return nil
+	}
+
+	err := conn.Close(ctx)
+
+	if err != nil {
+		log.Println("Failed to close the database connection:", err)
+	}
+
+	return err //nolint:wrapcheck
+}
+
 func execSimple(ctx context.Context, conn *pgconn.PgConn, sql string) error {
 	mrr := conn.Exec(ctx, sql)
 
@@ -17,6 +71,40 @@ func execSimple(ctx context.Context, conn *pgconn.PgConn, sql string) error {
 	return mrr.Close() //nolint:wrapcheck
 }
 
+func execMigrationSQL(ctx context.Context, conn *pgconn.PgConn, sql string) error {
+	if isConnectionInTransaction(conn) {
+		panic("the connection is not allowed to be in an active transaction")
+	}
+
+	if err := execSimple(ctx, conn, sql); err != nil {
+		return &ExecSQLError{Cause: err, SQL: sql}
+	}
+
+	if isConnectionInTransaction(conn) {
+		err := execSimple(ctx, conn, "ROLLBACK;")
+
+		return &TransactionNotCommittedError{RollBackError: err}
+	}
+
+	return nil
+}
+
+// setSearchPath sets the search_path of the session in which migrations run.
+// An empty list keeps the default of the database role.
+func setSearchPath(ctx context.Context, conn *pgx.Conn, schemas []string) error {
+	if len(schemas) == 0 {
+		return nil
+	}
+
+	sanitized := make([]string, 0, len(schemas))
+
+	for _, schema := range schemas {
+		sanitized = append(sanitized, pgx.Identifier{schema}.Sanitize())
+	}
+
+	return execSimple(ctx, conn.PgConn(), "SET search_path TO "+strings.Join(sanitized, ", ")+";")
+}
+
 func isConnectionInTransaction(conn *pgconn.PgConn) bool {
 	const (
 		inTransaction       = 'T'
@@ -33,3 +121,9 @@ func isPgErrorOfCode(err error, pgErrorCode string) bool {
 
 	return errors.As(err, &pgError) && pgError.Code == pgErrorCode
 }
+
+// isUndefinedTableError reports whether the bookkeeping table is missing,
+// including the case when its schema does not exist yet.
+func isUndefinedTableError(err error) bool {
+	return isPgErrorOfCode(err, pgerrcode.UndefinedTable) || isPgErrorOfCode(err, pgerrcode.InvalidSchemaName)
+}
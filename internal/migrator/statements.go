@@ -0,0 +1,130 @@
+package migrator
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/servletcloud/Andmerada/internal/settings"
+	"github.com/servletcloud/Andmerada/internal/sqlparse"
+)
+
+// execMigration executes the SQL prepared by prepareSQL in the given modes. In the auto transaction mode
+// the SQL runs between BEGIN and COMMIT issued by Andmerada, and beforeCommit, if set, runs right before
+// COMMIT, so that its changes are committed or rolled back together with the migration.
+// In the none mode beforeCommit is not called.
+func execMigration(
+	ctx context.Context,
+	conn *pgconn.PgConn,
+	sql string,
+	transaction settings.TransactionMode,
+	execution settings.ExecutionMode,
+	beforeCommit func(ctx context.Context) error,
+) error {
+	if transaction != settings.TransactionModeAuto {
+		if execution == settings.ExecutionModeStatement {
+			return execMigrationStatements(ctx, conn, sql)
+		}
+
+		return execMigrationSQL(ctx, conn, sql)
+	}
+
+	if isConnectionInTransaction(conn) {
+		panic("the connection is not allowed to be in an active transaction")
+	}
+
+	if err := execSimple(ctx, conn, "BEGIN;"); err != nil {
+		return &ExecSQLError{Cause: err, SQL: "BEGIN;"}
+	}
+
+	err := execManagedSQL(ctx, conn, sql, execution)
+
+	if err == nil && beforeCommit != nil {
+		err = beforeCommit(ctx)
+	}
+
+	if err != nil {
+		// Leave no aborted transaction behind for the callbacks and the history that run on this connection.
+		if rollbackErr := execSimple(ctx, conn, "ROLLBACK;"); rollbackErr != nil {
+			log.Println("Failed to roll back the migration:", rollbackErr)
+		}
+
+		return err
+	}
+
+	if err := execSimple(ctx, conn, "COMMIT;"); err != nil {
+		return &ExecSQLError{Cause: err, SQL: "COMMIT;"}
+	}
+
+	return nil
+}
+
+// execManagedSQL executes the SQL inside the transaction opened by execMigration.
+func execManagedSQL(ctx context.Context, conn *pgconn.PgConn, sql string, execution settings.ExecutionMode) error {
+	if execution == settings.ExecutionModeStatement {
+		return execStatements(ctx, conn, sql)
+	}
+
+	if err := execSimple(ctx, conn, sql); err != nil {
+		return &ExecSQLError{Cause: err, SQL: sql}
+	}
+
+	return nil
+}
+
+// execMigrationStatements executes the statements of the SQL one by one outside of a managed transaction.
+func execMigrationStatements(ctx context.Context, conn *pgconn.PgConn, sql string) error {
+	if isConnectionInTransaction(conn) {
+		panic("the connection is not allowed to be in an active transaction")
+	}
+
+	if err := execStatements(ctx, conn, sql); err != nil {
+		return err
+	}
+
+	if isConnectionInTransaction(conn) {
+		err := execSimple(ctx, conn, "ROLLBACK;")
+
+		return &TransactionNotCommittedError{RollBackError: err}
+	}
+
+	return nil
+}
+
+// execStatements executes the statements of the SQL one by one and logs the command tag and the duration of each.
+func execStatements(ctx context.Context, conn *pgconn.PgConn, sql string) error {
+	statements := sqlparse.Split(sql)
+
+	for i, statement := range statements {
+		startTime := time.Now()
+
+		commandTag, err := execStatement(ctx, conn, statement.Text)
+		if err != nil {
+			return &StatementError{
+				Cause:  &ExecSQLError{Cause: err, SQL: statement.Text},
+				Number: i + 1,
+				Count:  len(statements),
+				Line:   statement.Line,
+			}
+		}
+
+		log.Printf("  Statement %d of %d, line %d: %v in %s",
+			i+1, len(statements), statement.Line, commandTag, humanizeDuration(time.Since(startTime), "0ms"))
+	}
+
+	return nil
+}
+
+// execStatement executes one statement and returns its command tag, e.g. UPDATE 12034.
+func execStatement(ctx context.Context, conn *pgconn.PgConn, sql string) (pgconn.CommandTag, error) {
+	var commandTag pgconn.CommandTag
+
+	mrr := conn.Exec(ctx, sql)
+
+	for mrr.NextResult() {
+		commandTag, _ = mrr.ResultReader().Close()
+	}
+
+	return commandTag, mrr.Close() //nolint:wrapcheck
+}
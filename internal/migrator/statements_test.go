@@ -0,0 +1,83 @@
+package migrator_test
+
+import (
+	"testing"
+
+	"github.com/servletcloud/Andmerada/internal/migrator"
+	"github.com/servletcloud/Andmerada/internal/settings"
+	"github.com/servletcloud/Andmerada/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:paralleltest,funlen
+func TestStatementExecution(t *testing.T) {
+	connectionURL := tests.StartEmbeddedPostgres(t)
+	conn := tests.OpenPgConnection(t, connectionURL)
+
+	newOptions := func(dir string, transaction settings.TransactionMode) migrator.ApplyOptions {
+		options := newApplyOptions(connectionURL, dir)
+		options.Project.Configuration.Transaction = transaction
+		options.Project.Configuration.Execution = settings.ExecutionModeStatement
+		options.AllowDrift = true
+
+		return options
+	}
+
+	t.Run("Runs the statements one by one", func(t *testing.T) {
+		dir := t.TempDir()
+		result := tests.CreateSource(t, dir, "Statements", "20250501101010")
+		writeUpSQL(t, result.FullPath, `-- header; comment
+CREATE TABLE statements_1 (id INTEGER, note TEXT);
+INSERT INTO statements_1 VALUES (1, E'a\';b'), (2, 'c;d');
+DO $$ BEGIN UPDATE statements_1 SET id = id + 10; END $$;`)
+
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+		require.NoError(t, migrator.ApplyPending(t.Context(), newOptions(dir, settings.TransactionModeNone), &report))
+
+		var sum int
+
+		require.NoError(t, conn.QueryRow(t.Context(), "SELECT sum(id) FROM statements_1").Scan(&sum))
+		assert.Equal(t, 23, sum)
+	})
+
+	t.Run("Reports the failed statement and its line", func(t *testing.T) {
+		dir := t.TempDir()
+		result := tests.CreateSource(t, dir, "Broken", "20250502101010")
+		writeUpSQL(t, result.FullPath, "CREATE TABLE statements_2 (id INTEGER);\n\nSELECT 1;\nINSERT INTO\n  missing VALUES (1);")
+
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+		err := migrator.ApplyPending(t.Context(), newOptions(dir, settings.TransactionModeNone), &report)
+
+		var statementErr *migrator.StatementError
+
+		require.ErrorAs(t, err, &statementErr)
+		assert.Equal(t, 3, statementErr.Number)
+		assert.Equal(t, 3, statementErr.Count)
+		assert.Equal(t, 4, statementErr.Line)
+
+		var execSQLErr *migrator.ExecSQLError
+
+		require.ErrorAs(t, err, &execSQLErr)
+		assert.Equal(t, "INSERT INTO\n  missing VALUES (1);", execSQLErr.SQL)
+
+		// Without a transaction, the statements before the failed one stay committed.
+		tests.AssertPgTableExist(t, conn, "statements_2")
+	})
+
+	t.Run("The auto mode runs the statements in one transaction", func(t *testing.T) {
+		dir := t.TempDir()
+		result := tests.CreateSource(t, dir, "Managed", "20250503101010")
+		writeUpSQL(t, result.FullPath, "CREATE TABLE statements_3 (id INTEGER);\nINSERT INTO missing VALUES (1);")
+
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+		err := migrator.ApplyPending(t.Context(), newOptions(dir, settings.TransactionModeAuto), &report)
+
+		var statementErr *migrator.StatementError
+
+		require.ErrorAs(t, err, &statementErr)
+		assert.Equal(t, 2, statementErr.Line)
+
+		tests.AssertPgTableNotExist(t, conn, "statements_3")
+	})
+}
@@ -0,0 +1,188 @@
+package migrator
+
+import (
+	"cmp"
+	"context"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/servletcloud/Andmerada/internal/project"
+	"github.com/servletcloud/Andmerada/internal/source"
+)
+
+type MigrationState string
+
+const (
+	StateApplied         MigrationState = "applied"
+	StatePending         MigrationState = "pending"
+	StateMissingOnDisk   MigrationState = "applied-but-missing-on-disk"
+	StateChecksumChanged MigrationState = "checksum-changed"
+	// StateOutdated is a repeatable migration that changed since it was applied. The next migrate re-applies it.
+	StateOutdated MigrationState = "outdated"
+)
+
+type StatusEntry struct {
+	ID         source.ID      `json:"id"`
+	Name       string         `json:"name"`
+	State      MigrationState `json:"state"`
+	AppliedAt  *time.Time     `json:"applied_at"`
+	DurationMs *int64         `json:"duration_ms"`
+	Repeatable bool           `json:"repeatable"`
+}
+
+type StatusOptions struct {
+	MaxSQLFileSize int64
+	DatabaseURL    string
+	Project        project.Project
+}
+
+type StatusReport struct {
+	Entries []StatusEntry
+}
+
+func (report *StatusReport) CountOf(state MigrationState) int {
+	count := 0
+
+	for _, entry := range report.Entries {
+		if entry.State == state {
+			count++
+		}
+	}
+
+	return count
+}
+
+func Status(ctx context.Context, options StatusOptions, report *StatusReport) error {
+	report.Entries = nil
+
+	scan, err := scanDiskAndDatabase(ctx, options.DatabaseURL, options.Project)
+	if err != nil {
+		return err
+	}
+
+	sourceIDToName := scan.sourceIDToName
+
+	loader := source.Loader{MaxSQLFileSize: options.MaxSQLFileSize}
+	checker := driftChecker{projectDir: options.Project.Dir, loader: loader}
+
+	idToDrift := make(map[source.ID]Drift)
+	for _, drift := range checker.detect(scan.applied, sourceIDToName) {
+		idToDrift[drift.ID] = drift
+	}
+
+	for _, migration := range scan.applied {
+		report.Entries = append(report.Entries, StatusEntry{
+			ID:         migration.ID,
+			Name:       migration.Name,
+			State:      toMigrationState(idToDrift, migration.ID),
+			AppliedAt:  &migration.AppliedAt,
+			DurationMs: &migration.DurationMs,
+			Repeatable: false,
+		})
+
+		delete(sourceIDToName, migration.ID)
+	}
+
+	for id, dirName := range sourceIDToName {
+		report.Entries = append(report.Entries, StatusEntry{
+			ID:         id,
+			Name:       loadSourceName(&loader, filepath.Join(options.Project.Dir, dirName), dirName),
+			State:      StatePending,
+			AppliedAt:  nil,
+			DurationMs: nil,
+			Repeatable: false,
+		})
+	}
+
+	report.addRepeatableEntries(&loader, options.Project.Dir, scan)
+
+	// Versioned migrations come first in the order of IDs, followed by the repeatable ones
+	// in the order they are applied.
+	slices.SortFunc(report.Entries, func(a, b StatusEntry) int {
+		if a.Repeatable != b.Repeatable {
+			if a.Repeatable {
+				return 1
+			}
+
+			return -1
+		}
+
+		if a.Repeatable {
+			return cmp.Compare(a.Name, b.Name)
+		}
+
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return nil
+}
+
+func (report *StatusReport) addRepeatableEntries(loader *source.Loader, projectDir string, scan *projectScan) {
+	idToApplied := make(map[source.ID]*Migration, len(scan.appliedRepeatables))
+	for i := range scan.appliedRepeatables {
+		idToApplied[scan.appliedRepeatables[i].ID] = &scan.appliedRepeatables[i]
+	}
+
+	src := source.Source{} //nolint:exhaustruct
+
+	for _, name := range scan.repeatableNames {
+		entry := StatusEntry{
+			ID:         source.RepeatableID(name),
+			Name:       name,
+			State:      StatePending,
+			AppliedAt:  nil,
+			DurationMs: nil,
+			Repeatable: true,
+		}
+
+		if migration, found := idToApplied[entry.ID]; found {
+			entry.AppliedAt = &migration.AppliedAt
+			entry.DurationMs = &migration.DurationMs
+			entry.State = StateApplied
+
+			err := loader.LoadRepeatable(projectDir, name, &src)
+			if err != nil || migration.SQLUpSHA256 != Sha256ToHexStr(src.UpSQL) {
+				entry.State = StateOutdated
+			}
+
+			delete(idToApplied, entry.ID)
+		}
+
+		report.Entries = append(report.Entries, entry)
+	}
+
+	for _, migration := range idToApplied {
+		report.Entries = append(report.Entries, StatusEntry{
+			ID:         migration.ID,
+			Name:       migration.Name,
+			State:      StateMissingOnDisk,
+			AppliedAt:  &migration.AppliedAt,
+			DurationMs: &migration.DurationMs,
+			Repeatable: true,
+		})
+	}
+}
+
+func toMigrationState(idToDrift map[source.ID]Drift, id source.ID) MigrationState {
+	drift, found := idToDrift[id]
+
+	switch {
+	case !found:
+		return StateApplied
+	case drift.Kind == DriftModified:
+		return StateChecksumChanged
+	default:
+		return StateMissingOnDisk
+	}
+}
+
+func loadSourceName(loader *source.Loader, dir string, fallback string) string {
+	configuration := source.Configuration{} //nolint:exhaustruct
+
+	if err := loader.LoadConfiguration(dir, &configuration); err != nil {
+		return fallback
+	}
+
+	return configuration.Name
+}
@@ -0,0 +1,88 @@
+package migrator_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/servletcloud/Andmerada/internal/migrator"
+	"github.com/servletcloud/Andmerada/internal/project"
+	"github.com/servletcloud/Andmerada/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:paralleltest,funlen
+func TestStatus(t *testing.T) {
+	connectionURL := tests.StartEmbeddedPostgres(t)
+	dir := t.TempDir()
+	testProject := project.Project{Dir: dir, Configuration: createProjectConfig(), Callbacks: nil}
+
+	statusOptions := migrator.StatusOptions{
+		MaxSQLFileSize: 1024,
+		DatabaseURL:    string(connectionURL),
+		Project:        testProject,
+	}
+
+	status := func(t *testing.T) migrator.StatusReport {
+		t.Helper()
+
+		report := migrator.StatusReport{Entries: nil}
+		require.NoError(t, migrator.Status(t.Context(), statusOptions, &report))
+
+		return report
+	}
+
+	first := tests.CreateSource(t, dir, "First", "20250301101010")
+	second := tests.CreateSource(t, dir, "Second", "20250301101011")
+	writeUpSQL(t, first.FullPath, "SELECT 1;")
+	writeUpSQL(t, second.FullPath, "SELECT 2;")
+
+	t.Run("All migrations are pending before the first migrate", func(t *testing.T) {
+		report := status(t)
+
+		require.Len(t, report.Entries, 2)
+		assert.Equal(t, 2, report.CountOf(migrator.StatePending))
+		assert.Equal(t, "First", report.Entries[0].Name)
+		assert.Nil(t, report.Entries[0].AppliedAt)
+	})
+
+	applyOptions := newApplyOptions(connectionURL, dir)
+	applyReport := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+	require.NoError(t, migrator.ApplyPending(t.Context(), applyOptions, &applyReport))
+
+	third := tests.CreateSource(t, dir, "Third", "20250301101012")
+	writeUpSQL(t, third.FullPath, "SELECT 3;")
+
+	t.Run("Reports applied and pending migrations in ID order", func(t *testing.T) {
+		report := status(t)
+
+		require.Len(t, report.Entries, 3)
+
+		states := []migrator.MigrationState{
+			report.Entries[0].State,
+			report.Entries[1].State,
+			report.Entries[2].State,
+		}
+
+		assert.Equal(t, []migrator.MigrationState{
+			migrator.StateApplied,
+			migrator.StateApplied,
+			migrator.StatePending,
+		}, states)
+
+		assert.NotNil(t, report.Entries[0].AppliedAt)
+		assert.NotNil(t, report.Entries[0].DurationMs)
+	})
+
+	t.Run("Reports changed and missing migrations", func(t *testing.T) {
+		writeUpSQL(t, first.FullPath, "SELECT 10;")
+		require.NoError(t, os.RemoveAll(second.FullPath))
+
+		report := status(t)
+
+		require.Len(t, report.Entries, 3)
+		assert.Equal(t, migrator.StateChecksumChanged, report.Entries[0].State)
+		assert.Equal(t, migrator.StateMissingOnDisk, report.Entries[1].State)
+		assert.Equal(t, "Second", report.Entries[1].Name)
+	})
+}
@@ -0,0 +1,60 @@
+package migrator
+
+import (
+	"github.com/servletcloud/Andmerada/internal/settings"
+	"github.com/servletcloud/Andmerada/internal/sqlparse"
+)
+
+// prepareSQL returns the SQL to execute in the given transaction mode. In the auto mode the SQL is only checked,
+// because execMigration issues BEGIN and COMMIT itself, so the SQL must not contain statements that control
+// transactions or cannot run inside a transaction block.
+func prepareSQL(transaction settings.TransactionMode, sql string) (string, error) {
+	if transaction == settings.TransactionModeAuto {
+		if err := checkManagedTransaction(sql); err != nil {
+			return "", err
+		}
+	}
+
+	return sql, nil
+}
+
+// isSingleTransaction reports whether a failure of the prepared SQL leaves no changes behind.
+// In the statement execution mode every statement outside of a transaction block commits on its own,
+// so a script without transaction control qualifies only when Andmerada manages the transaction.
+func isSingleTransaction(
+	transaction settings.TransactionMode,
+	execution settings.ExecutionMode,
+	script *sqlparse.Script,
+) bool {
+	if !script.IsSingleTransaction() {
+		return false
+	}
+
+	if execution != settings.ExecutionModeStatement || transaction == settings.TransactionModeAuto {
+		return true
+	}
+
+	return script.HasTransactionControl() || len(script.Statements) <= 1
+}
+
+func checkManagedTransaction(sql string) error {
+	script := sqlparse.Parse(sql)
+
+	if statement, found := script.FirstTransactionControl(); found {
+		return &ManagedTransactionError{
+			Statement: statement.Text,
+			Line:      statement.Line,
+			Reason:    "Transaction control is not allowed when Andmerada manages the transaction",
+		}
+	}
+
+	if statement, found := script.FirstNonTransactional(); found {
+		return &ManagedTransactionError{
+			Statement: statement.Text,
+			Line:      statement.Line,
+			Reason:    "The statement cannot run inside a transaction block",
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package migrator_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/servletcloud/Andmerada/internal/migrator"
+	"github.com/servletcloud/Andmerada/internal/settings"
+	"github.com/servletcloud/Andmerada/internal/source"
+	"github.com/servletcloud/Andmerada/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:paralleltest,funlen
+func TestManagedTransaction(t *testing.T) {
+	connectionURL := tests.StartEmbeddedPostgres(t)
+	conn := tests.OpenPgConnection(t, connectionURL)
+
+	newOptions := func(dir string, transaction settings.TransactionMode) migrator.ApplyOptions {
+		options := newApplyOptions(connectionURL, dir)
+		options.Project.Configuration.Transaction = transaction
+		options.AllowDrift = true
+
+		return options
+	}
+
+	t.Run("The auto mode wraps the SQL in a transaction", func(t *testing.T) {
+		dir := t.TempDir()
+		result := tests.CreateSource(t, dir, "Auto", "20250401101010")
+		writeUpSQL(t, result.FullPath, "CREATE TABLE auto_1 (id INTEGER);\nSELECT txid_current_if_assigned() -- no semicolon")
+
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+		err := migrator.ApplyPending(t.Context(), newOptions(dir, settings.TransactionModeAuto), &report)
+		require.NoError(t, err)
+
+		tests.AssertPgTableExist(t, conn, "auto_1")
+	})
+
+	t.Run("The auto mode rejects transaction control before anything runs", func(t *testing.T) {
+		dir := t.TempDir()
+		first := tests.CreateSource(t, dir, "First", "20250402101010")
+		writeUpSQL(t, first.FullPath, "CREATE TABLE auto_2 (id INTEGER);")
+
+		second := tests.CreateSource(t, dir, "Second", "20250403101010")
+		writeUpSQL(t, second.FullPath, "BEGIN;\nCREATE TABLE auto_3 (id INTEGER);\nCOMMIT;")
+
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+		err := migrator.ApplyPending(t.Context(), newOptions(dir, settings.TransactionModeAuto), &report)
+
+		var transactionErr *migrator.ManagedTransactionError
+
+		require.ErrorAs(t, err, &transactionErr)
+		assert.Equal(t, 1, transactionErr.Line)
+		assert.Equal(t, "BEGIN;", transactionErr.Statement)
+
+		tests.AssertPgTableNotExist(t, conn, "auto_2")
+		tests.AssertPgTableNotExist(t, conn, "auto_3")
+	})
+
+	t.Run("migration.yml overrides the project default", func(t *testing.T) {
+		dir := t.TempDir()
+		result := tests.CreateSource(t, dir, "Concurrently", "20250404101010")
+		writeUpSQL(t, result.FullPath, "CREATE INDEX CONCURRENTLY idx_auto_1 ON auto_1 (id);")
+		appendToMigrationYml(t, result.FullPath, "transaction: none\n")
+
+		report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+		err := migrator.ApplyPending(t.Context(), newOptions(dir, settings.TransactionModeAuto), &report)
+		require.NoError(t, err)
+	})
+}
+
+func appendToMigrationYml(t *testing.T, dir string, content string) {
+	t.Helper()
+
+	path := filepath.Join(dir, source.MigrationYmlFilename)
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+
+	_, err = file.WriteString(content)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+}
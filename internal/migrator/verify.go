@@ -0,0 +1,204 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/servletcloud/Andmerada/internal/project"
+	"github.com/servletcloud/Andmerada/internal/source"
+)
+
+type DriftKind int
+
+const (
+	DriftModified DriftKind = iota
+	DriftMissingOnDisk
+	DriftUnreadable
+)
+
+type Drift struct {
+	ID      source.ID
+	Name    string
+	Kind    DriftKind
+	Details string
+}
+
+type VerifyOptions struct {
+	MaxSQLFileSize int64
+	DatabaseURL    string
+	Project        project.Project
+	AllowDrift     bool
+}
+
+type VerifyReport struct {
+	AppliedCount int
+	Drifts       []Drift
+}
+
+func (kind DriftKind) String() string {
+	switch kind {
+	case DriftModified:
+		return "modified"
+	case DriftMissingOnDisk:
+		return "missing-on-disk"
+	case DriftUnreadable:
+		return "unreadable"
+	default:
+		return "unknown"
+	}
+}
+
+func Verify(ctx context.Context, options VerifyOptions, report *VerifyReport) error {
+	report.AppliedCount = 0
+	report.Drifts = nil
+
+	scan, err := scanDiskAndDatabase(ctx, options.DatabaseURL, options.Project)
+	if err != nil {
+		return err
+	}
+
+	checker := driftChecker{
+		projectDir: options.Project.Dir,
+		loader:     source.Loader{MaxSQLFileSize: options.MaxSQLFileSize},
+	}
+
+	report.AppliedCount = len(scan.applied)
+	report.Drifts = checker.detect(scan.applied, scan.sourceIDToName)
+
+	return checkDrifts(report.Drifts, options.AllowDrift)
+}
+
+// projectScan holds the migrations found on disk together with the ones recorded in the database.
+type projectScan struct {
+	sourceIDToName     map[source.ID]string
+	applied            []Migration
+	repeatableNames    []string
+	appliedRepeatables []Migration
+}
+
+func scanDiskAndDatabase(ctx context.Context, databaseURL string, project project.Project) (*projectScan, error) {
+	sourceIDToName, err := source.ScanAll(project.Dir)
+	if err != nil {
+		return nil, wrapError(err, ErrTypeListMigrationsOnDisk)
+	}
+
+	repeatableNames, err := source.ScanRepeatables(project.Dir)
+	if err != nil {
+		return nil, wrapError(err, ErrTypeListMigrationsOnDisk)
+	}
+
+	connection, err := connect(ctx, databaseURL, project.Configuration.SearchPath)
+
+	defer closeConnection(ctx, connection) //nolint:errcheck
+
+	if err != nil {
+		return nil, wrapError(err, ErrTypeDBConnect)
+	}
+
+	migrationsRepo := &Migrations{
+		TableName:    project.Configuration.MigrationsTableName,
+		CreateSchema: project.Configuration.CreateSchema,
+	}
+
+	if _, err := checkSchemaVersion(ctx, connection, migrationsRepo); err != nil {
+		return nil, err
+	}
+
+	applied, err := migrationsRepo.ListApplied(ctx, connection)
+	if err != nil && !isUndefinedTableError(err) {
+		return nil, wrapError(err, ErrTypeScanAppliedMigrations)
+	}
+
+	appliedRepeatables, err := migrationsRepo.ListRepeatables(ctx, connection)
+	if err != nil && !isUndefinedTableError(err) {
+		return nil, wrapError(err, ErrTypeScanAppliedMigrations)
+	}
+
+	return &projectScan{
+		sourceIDToName:     sourceIDToName,
+		applied:            applied,
+		repeatableNames:    repeatableNames,
+		appliedRepeatables: appliedRepeatables,
+	}, nil
+}
+
+func checkDrifts(drifts []Drift, allowDrift bool) error {
+	if len(drifts) == 0 || allowDrift {
+		return nil
+	}
+
+	return wrapError(&ChecksumDriftError{Drifts: drifts}, ErrTypeChecksumDrift)
+}
+
+type driftChecker struct {
+	projectDir string
+	loader     source.Loader
+}
+
+func (checker *driftChecker) detect(applied []Migration, sourceIDToName map[source.ID]string) []Drift {
+	var drifts []Drift
+
+	source := source.Source{} //nolint:exhaustruct
+
+	for _, migration := range applied {
+		drift := Drift{ID: migration.ID, Name: migration.Name, Kind: DriftMissingOnDisk, Details: ""}
+
+		dirName, found := sourceIDToName[migration.ID]
+
+		if !found {
+			drift.Details = "The migration is applied, but its directory is not found on disk."
+			drifts = append(drifts, drift)
+
+			continue
+		}
+
+		if err := checker.loader.LoadSource(filepath.Join(checker.projectDir, dirName), &source); err != nil {
+			drift.Kind = DriftUnreadable
+			drift.Details = checker.describeLoadError(dirName, err)
+			drifts = append(drifts, drift)
+
+			continue
+		}
+
+		if details, modified := checker.compareChecksums(&migration, &source); modified {
+			drift.Kind = DriftModified
+			drift.Details = details
+			drifts = append(drifts, drift)
+		}
+	}
+
+	return drifts
+}
+
+func (checker *driftChecker) describeLoadError(dirName string, err error) string {
+	if errors.Is(err, os.ErrNotExist) {
+		return "Migration files in " + dirName + " are missing: " + err.Error()
+	}
+
+	return "Migration files in " + dirName + " cannot be loaded: " + err.Error()
+}
+
+func (checker *driftChecker) compareChecksums(migration *Migration, source *source.Source) (string, bool) {
+	upModified := migration.SQLUpSHA256 != Sha256ToHexStr(source.UpSQL)
+	downModified := migration.SQLDownSHA256 != Sha256ToHexStr(source.DownSQL)
+
+	switch {
+	case upModified && downModified:
+		return "Both up and down SQL were modified after the migration was applied.", true
+	case upModified:
+		return "Up SQL was modified after the migration was applied.", true
+	case downModified:
+		return "Down SQL was modified after the migration was applied.", true
+	default:
+		return "", false
+	}
+}
+
+func logDrifts(drifts []Drift) {
+	for _, drift := range drifts {
+		log.Printf("Warning: %v %q is %v. %s", drift.ID, drift.Name, drift.Kind, drift.Details)
+	}
+}
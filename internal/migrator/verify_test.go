@@ -0,0 +1,127 @@
+package migrator_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/servletcloud/Andmerada/internal/migrator"
+	"github.com/servletcloud/Andmerada/internal/project"
+	"github.com/servletcloud/Andmerada/internal/source"
+	"github.com/servletcloud/Andmerada/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:paralleltest,funlen
+func TestVerify(t *testing.T) {
+	connectionURL := tests.StartEmbeddedPostgres(t)
+	dir := t.TempDir()
+	testProject := project.Project{Dir: dir, Configuration: createProjectConfig(), Callbacks: nil}
+
+	applyOptions := newApplyOptions(connectionURL, dir)
+
+	verifyOptions := migrator.VerifyOptions{
+		MaxSQLFileSize: 1024,
+		DatabaseURL:    string(connectionURL),
+		Project:        testProject,
+		AllowDrift:     false,
+	}
+
+	verify := func(t *testing.T, options migrator.VerifyOptions) (migrator.VerifyReport, error) {
+		t.Helper()
+
+		report := migrator.VerifyReport{AppliedCount: 0, Drifts: nil}
+		err := migrator.Verify(t.Context(), options, &report)
+
+		return report, err
+	}
+
+	t.Run("No drift when the migrations table does not exist", func(t *testing.T) {
+		report, err := verify(t, verifyOptions)
+
+		require.NoError(t, err)
+		assert.Empty(t, report.Drifts)
+	})
+
+	modified := tests.CreateSource(t, dir, "Modified", "20250110093012")
+	missing := tests.CreateSource(t, dir, "Missing", "20250110093013")
+	unknown := tests.CreateSource(t, dir, "Unknown", "20250110093014")
+	intact := tests.CreateSource(t, dir, "Intact", "20250110093015")
+	blocked := tests.CreateSource(t, dir, "Blocked", "20250110093016")
+
+	for _, result := range []source.CreateSourceResult{modified, missing, unknown, intact, blocked} {
+		writeUpSQL(t, result.FullPath, "SELECT 1;")
+	}
+
+	// A blocked migration after non-blocked ones must not inherit their down.sql.
+	blockedYml := filepath.Join(blocked.FullPath, source.MigrationYmlFilename)
+	content, err := os.ReadFile(blockedYml)
+	require.NoError(t, err)
+	blockedContent := bytes.Replace(content, []byte("block: false"), []byte("block: true"), 1)
+	require.NoError(t, os.WriteFile(blockedYml, blockedContent, 0600))
+
+	report := migrator.Report{PendingCount: 0, LatestAppliedID: 0, OutOfOrderIDs: nil, SkippedIDs: nil, Entries: nil}
+	require.NoError(t, migrator.ApplyPending(t.Context(), applyOptions, &report))
+
+	t.Run("No drift right after applying", func(t *testing.T) {
+		report, err := verify(t, verifyOptions)
+
+		require.NoError(t, err)
+		assert.Equal(t, 5, report.AppliedCount)
+		assert.Empty(t, report.Drifts)
+	})
+
+	writeUpSQL(t, modified.FullPath, "SELECT 2;")
+	require.NoError(t, os.Remove(filepath.Join(missing.FullPath, source.UpSQLFilename)))
+	require.NoError(t, os.RemoveAll(unknown.FullPath))
+
+	t.Run("Reports modified, unreadable and missing migrations", func(t *testing.T) {
+		report, err := verify(t, verifyOptions)
+
+		var applierErr *migrator.MigrateError
+
+		require.ErrorAs(t, err, &applierErr)
+		assert.Equal(t, migrator.ErrTypeChecksumDrift, applierErr.ErrType)
+
+		kinds := make(map[source.ID]migrator.DriftKind)
+		for _, drift := range report.Drifts {
+			kinds[drift.ID] = drift.Kind
+		}
+
+		assert.Equal(t, map[source.ID]migrator.DriftKind{
+			20250110093012: migrator.DriftModified,
+			20250110093013: migrator.DriftUnreadable,
+			20250110093014: migrator.DriftMissingOnDisk,
+		}, kinds)
+	})
+
+	t.Run("Succeeds when drift is allowed", func(t *testing.T) {
+		optionsCopy := verifyOptions
+		optionsCopy.AllowDrift = true
+
+		report, err := verify(t, optionsCopy)
+
+		require.NoError(t, err)
+		assert.Len(t, report.Drifts, 3)
+	})
+
+	t.Run("Migrate fails on drift before applying anything", func(t *testing.T) {
+		pending := tests.CreateSource(t, dir, "Pending", "20250110093017")
+		writeUpSQL(t, pending.FullPath, "CREATE TABLE verify_pending (id INTEGER);")
+
+		err := migrator.ApplyPending(t.Context(), applyOptions, &report)
+
+		var driftErr *migrator.ChecksumDriftError
+
+		require.ErrorAs(t, err, &driftErr)
+		assert.Len(t, driftErr.Drifts, 3)
+
+		optionsCopy := applyOptions
+		optionsCopy.AllowDrift = true
+
+		require.NoError(t, migrator.ApplyPending(t.Context(), optionsCopy, &report))
+		assert.Equal(t, 1, report.PendingCount)
+	})
+}
@@ -0,0 +1,135 @@
+// Package placeholder substitutes ${name} placeholders in SQL, so that the same migrations can run
+// against environments with different schemas and roles.
+package placeholder
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	MigrationID     = "migration_id"
+	MigrationName   = "migration_name"
+	MigrationsTable = "migrations_table"
+
+	// EnvPrefix is the prefix of environment variables that define placeholders,
+	// e.g. ANDMERADA_PLACEHOLDER_APP_OWNER defines ${app_owner}.
+	EnvPrefix = "ANDMERADA_PLACEHOLDER_"
+)
+
+// pattern matches ${name} and its escaped form $${name}, which is kept as the literal ${name}.
+var pattern = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Values maps placeholder names to the text they are replaced with.
+type Values map[string]string
+
+type UnresolvedError struct {
+	Names []string
+}
+
+func (e *UnresolvedError) Error() string {
+	quoted := make([]string, 0, len(e.Names))
+
+	for _, name := range e.Names {
+		quoted = append(quoted, "${"+name+"}")
+	}
+
+	return fmt.Sprintf("unresolved placeholders: %s", strings.Join(quoted, ", "))
+}
+
+// Merge combines the layers of values. A later layer overrides the values of the earlier ones.
+func Merge(layers ...Values) Values {
+	result := make(Values)
+
+	for _, layer := range layers {
+		maps.Copy(result, layer)
+	}
+
+	return result
+}
+
+// FromEnviron returns the placeholders defined by environment variables with EnvPrefix.
+// Names are lowercased, because environment variables are conventionally uppercase.
+func FromEnviron(environ []string) Values {
+	result := make(Values)
+
+	for _, variable := range environ {
+		key, value, _ := strings.Cut(variable, "=")
+
+		if name, found := strings.CutPrefix(key, EnvPrefix); found && name != "" {
+			result[strings.ToLower(name)] = value
+		}
+	}
+
+	return result
+}
+
+// Names returns the distinct names of the placeholders in the SQL in the order of appearance.
+func Names(sql string) []string {
+	var names []string
+
+	for _, match := range pattern.FindAllStringSubmatch(sql, -1) {
+		if isEscaped(match[0]) || slices.Contains(names, match[1]) {
+			continue
+		}
+
+		names = append(names, match[1])
+	}
+
+	return names
+}
+
+// Unresolved returns the names of the placeholders in the SQL that have no value.
+// Built-in placeholders count as resolved.
+func Unresolved(sql string, values Values) []string {
+	var result []string
+
+	for _, name := range Names(sql) {
+		if _, found := values[name]; !found && !IsBuiltIn(name) {
+			result = append(result, name)
+		}
+	}
+
+	return result
+}
+
+func IsBuiltIn(name string) bool {
+	return name == MigrationID || name == MigrationName || name == MigrationsTable
+}
+
+// Replace substitutes the placeholders in the SQL. It fails when any of them has no value.
+func Replace(sql string, values Values) (string, error) {
+	var unresolved []string
+
+	result := pattern.ReplaceAllStringFunc(sql, func(match string) string {
+		if isEscaped(match) {
+			return match[1:]
+		}
+
+		name := match[2 : len(match)-1]
+
+		value, found := values[name]
+		if !found {
+			if !slices.Contains(unresolved, name) {
+				unresolved = append(unresolved, name)
+			}
+
+			return match
+		}
+
+		return value
+	})
+
+	if len(unresolved) > 0 {
+		return "", &UnresolvedError{Names: unresolved}
+	}
+
+	return result, nil
+}
+
+func isEscaped(match string) bool {
+	return strings.HasPrefix(match, "$$")
+}
@@ -0,0 +1,77 @@
+package placeholder_test
+
+import (
+	"testing"
+
+	"github.com/servletcloud/Andmerada/internal/placeholder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplace(t *testing.T) {
+	t.Parallel()
+
+	values := placeholder.Values{"app_owner": "app_owner_stg", "schema": "app"}
+
+	t.Run("replaces placeholders", func(t *testing.T) {
+		t.Parallel()
+
+		sql, err := placeholder.Replace("GRANT SELECT ON ${schema}.users TO ${app_owner};", values)
+		require.NoError(t, err)
+
+		assert.Equal(t, "GRANT SELECT ON app.users TO app_owner_stg;", sql)
+	})
+
+	t.Run("keeps escaped placeholders and dollar quotes", func(t *testing.T) {
+		t.Parallel()
+
+		sql, err := placeholder.Replace("SELECT '$${schema}', $$ ${schema} $$;", values)
+		require.NoError(t, err)
+
+		assert.Equal(t, "SELECT '${schema}', $$ app $$;", sql)
+	})
+
+	t.Run("fails on unresolved placeholders", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := placeholder.Replace("SELECT ${missing}, ${schema}, ${other}, ${missing};", values)
+
+		var unresolvedErr *placeholder.UnresolvedError
+
+		require.ErrorAs(t, err, &unresolvedErr)
+		assert.Equal(t, []string{"missing", "other"}, unresolvedErr.Names)
+		assert.Equal(t, "unresolved placeholders: ${missing}, ${other}", err.Error())
+	})
+}
+
+func TestUnresolved(t *testing.T) {
+	t.Parallel()
+
+	sql := "SELECT ${migration_id}, ${app_owner}, ${missing}, $${escaped};"
+
+	assert.Equal(t, []string{"missing"}, placeholder.Unresolved(sql, placeholder.Values{"app_owner": "owner"}))
+}
+
+func TestFromEnviron(t *testing.T) {
+	t.Parallel()
+
+	environ := []string{
+		"ANDMERADA_PLACEHOLDER_APP_OWNER=app_owner_stg",
+		"ANDMERADA_PLACEHOLDER_=ignored",
+		"PATH=/usr/bin",
+	}
+
+	assert.Equal(t, placeholder.Values{"app_owner": "app_owner_stg"}, placeholder.FromEnviron(environ))
+}
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+
+	merged := placeholder.Merge(
+		placeholder.Values{"a": "yml", "b": "yml"},
+		placeholder.Values{"b": "env", "c": "env"},
+		placeholder.Values{"c": "set"},
+	)
+
+	assert.Equal(t, placeholder.Values{"a": "yml", "b": "env", "c": "set"}, merged)
+}
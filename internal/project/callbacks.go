@@ -0,0 +1,74 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Callback is a lifecycle event of 'andmerada migrate' at which the SQL file of the same name
+// in the callbacks directory is executed.
+type Callback string
+
+const (
+	CallbackBeforeMigrate Callback = "before_migrate"
+	CallbackBeforeEach    Callback = "before_each"
+	CallbackAfterEach     Callback = "after_each"
+	CallbackAfterMigrate  Callback = "after_migrate"
+	CallbackAfterError    Callback = "after_error"
+
+	CallbacksDirName = "callbacks"
+
+	callbackExt = ".sql"
+)
+
+// Callbacks maps the callbacks present in the project to the paths of their SQL files.
+type Callbacks map[Callback]string
+
+var (
+	ErrUnknownCallback = errors.New("unknown callback")
+
+	knownCallbacks = []Callback{
+		CallbackBeforeMigrate,
+		CallbackBeforeEach,
+		CallbackAfterEach,
+		CallbackAfterMigrate,
+		CallbackAfterError,
+	}
+)
+
+func loadCallbacks(projectDir string) (Callbacks, error) {
+	dir := filepath.Join(projectDir, CallbacksDirName)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Callbacks{}, nil
+		}
+
+		return nil, fmt.Errorf("cannot read directory %v because: %w", dir, err)
+	}
+
+	callbacks := make(Callbacks)
+
+	for _, entry := range entries {
+		name, isSQL := strings.CutSuffix(entry.Name(), callbackExt)
+
+		if entry.IsDir() || !isSQL {
+			continue
+		}
+
+		callback := Callback(name)
+
+		if !slices.Contains(knownCallbacks, callback) {
+			return nil, fmt.Errorf("%w %q in %v, expected one of %v", ErrUnknownCallback, entry.Name(), dir, knownCallbacks)
+		}
+
+		callbacks[callback] = filepath.Join(dir, entry.Name())
+	}
+
+	return callbacks, nil
+}
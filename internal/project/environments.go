@@ -0,0 +1,93 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+
+	"github.com/servletcloud/Andmerada/internal/placeholder"
+	"github.com/servletcloud/Andmerada/internal/settings"
+)
+
+// Environment is a named deployment target of the project, e.g. dev, staging or prod.
+// It overrides the project configuration and provides the defaults of command flags.
+type Environment struct {
+	Name                string                    `yaml:"-"`
+	DatabaseURL         string                    `yaml:"database_url"`
+	MigrationsTableName string                    `yaml:"migrations_table_name"`
+	Placeholders        placeholder.Values        `yaml:"placeholders"`
+	OutOfOrder          settings.OutOfOrderPolicy `yaml:"out_of_order"`
+	Filter              string                    `yaml:"filter"`
+	DryRun              *bool                     `yaml:"dry_run"`
+	SkipPreValidation   *bool                     `yaml:"skip_prevalidation"`
+	AllowDrift          *bool                     `yaml:"allow_drift"`
+	FailOnWarning       *bool                     `yaml:"fail_on_warning"`
+	Protected           bool                      `yaml:"protected"`
+}
+
+var (
+	ErrUnknownEnvironment   = errors.New("unknown environment")
+	ErrUndefinedEnvVariable = errors.New("undefined environment variable")
+
+	// envVariablePattern matches ${NAME} references to environment variables in database_url.
+	envVariablePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+)
+
+// UseEnvironment applies the environment of the given name to the configuration of the project
+// and returns it with ${NAME} references to environment variables in its database URL expanded.
+func (p *Project) UseEnvironment(name string) (Environment, error) {
+	environment, found := p.Configuration.Environments[name]
+	if !found {
+		names := slices.Sorted(maps.Keys(p.Configuration.Environments))
+
+		return Environment{}, fmt.Errorf("%w %q, expected one of %v", ErrUnknownEnvironment, name, names)
+	}
+
+	databaseURL, err := expandEnvVariables(environment.DatabaseURL)
+	if err != nil {
+		return Environment{}, fmt.Errorf("cannot expand database_url of environment %q: %w", name, err)
+	}
+
+	environment.Name = name
+	environment.DatabaseURL = databaseURL
+
+	if environment.MigrationsTableName != "" {
+		p.Configuration.MigrationsTableName = environment.MigrationsTableName
+	}
+
+	if environment.OutOfOrder != "" {
+		p.Configuration.OutOfOrder = environment.OutOfOrder
+	}
+
+	if environment.FailOnWarning != nil {
+		p.Configuration.FailOnWarning = *environment.FailOnWarning
+	}
+
+	p.Configuration.Placeholders = placeholder.Merge(p.Configuration.Placeholders, environment.Placeholders)
+
+	return environment, nil
+}
+
+func expandEnvVariables(value string) (string, error) {
+	var undefined []string
+
+	result := envVariablePattern.ReplaceAllStringFunc(value, func(match string) string {
+		name := match[2 : len(match)-1]
+
+		variable, found := os.LookupEnv(name)
+		if !found {
+			undefined = append(undefined, name)
+		}
+
+		return variable
+	})
+
+	if len(undefined) > 0 {
+		return "", fmt.Errorf("%w: %v", ErrUndefinedEnvVariable, undefined)
+	}
+
+	return result, nil
+}
@@ -7,22 +7,38 @@ import (
 	"path/filepath"
 
 	"github.com/servletcloud/Andmerada/internal/osutil"
+	"github.com/servletcloud/Andmerada/internal/placeholder"
 	"github.com/servletcloud/Andmerada/internal/resources"
 	"github.com/servletcloud/Andmerada/internal/schema"
+	"github.com/servletcloud/Andmerada/internal/settings"
 	"github.com/servletcloud/Andmerada/internal/ymlutil"
 )
 
 const (
-	rootConfigFilename = "andmerada.yml"
+	rootConfigFilename  = "andmerada.yml"
+	localConfigFilename = "andmerada.local.yml"
 )
 
 type Project struct {
 	Dir           string
 	Configuration Configuration
+	Callbacks     Callbacks
 }
 
 type Configuration struct {
-	MigrationsTableName string `yaml:"migrations_table_name"`
+	MigrationsTableName string                    `yaml:"migrations_table_name"`
+	CreateSchema        bool                      `yaml:"create_schema"`
+	SearchPath          []string                  `yaml:"search_path"`
+	Placeholders        placeholder.Values        `yaml:"placeholders"`
+	Transaction         settings.TransactionMode  `yaml:"transaction"`
+	Execution           settings.ExecutionMode    `yaml:"execution"`
+	Isolation           settings.IsolationMode    `yaml:"isolation"`
+	FailOnWarning       bool                      `yaml:"fail_on_warning"`
+	OutOfOrder          settings.OutOfOrderPolicy `yaml:"out_of_order"`
+	Retry               settings.Retry            `yaml:"retry"`
+	Session             settings.Session          `yaml:"session"`
+	SelfUpgrade         settings.SelfUpgradeMode  `yaml:"self_upgrade"`
+	Environments        map[string]Environment    `yaml:"environments"`
 }
 
 var (
@@ -34,7 +50,7 @@ func Initialize(dir string) error {
 		return fmt.Errorf("failed to create directory %s: %w", dir, err)
 	}
 
-	configPath := filepath.Join(dir, rootConfigFilename)
+	configPath := ConfigPath(dir)
 
 	content := resources.TemplateAndmeradaYml()
 
@@ -49,14 +65,55 @@ func Initialize(dir string) error {
 	return nil
 }
 
+// Find returns the directory of the project that contains dir, searching dir and then its parents
+// for andmerada.yml the way git finds .git. The error wraps os.ErrNotExist when there is none.
+func Find(dir string) (string, error) {
+	current, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve directory %v: %w", dir, err)
+	}
+
+	for {
+		_, err := os.Stat(filepath.Join(current, rootConfigFilename))
+		if err == nil {
+			return current, nil
+		}
+
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("cannot look for %v in %v: %w", rootConfigFilename, current, err)
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			return "", fmt.Errorf("no %v in %v or any of its parents: %w", rootConfigFilename, dir, os.ErrNotExist)
+		}
+
+		current = parent
+	}
+}
+
+// ConfigPath returns the path of andmerada.yml of the project in dir.
+func ConfigPath(dir string) string {
+	return filepath.Join(dir, rootConfigFilename)
+}
+
+// Load reads andmerada.yml of the project in dir. An optional andmerada.local.yml next to it, which is
+// meant to be git-ignored, is merged on top of it.
 func Load(dir string) (Project, error) {
-	configFileName := filepath.Join(dir, rootConfigFilename)
+	configFileName := ConfigPath(dir)
+	localConfigFileName := filepath.Join(dir, localConfigFilename)
 
 	var configuration Configuration
 
-	if err := ymlutil.LoadFromFile(configFileName, schema.GetAndmeradaSchema(), &configuration); err != nil {
+	err := ymlutil.LoadWithOverlay(configFileName, localConfigFileName, schema.GetAndmeradaSchema(), &configuration)
+	if err != nil {
 		return Project{}, fmt.Errorf("failed to load project configuration file %q: %w", configFileName, err)
 	}
 
-	return Project{Dir: dir, Configuration: configuration}, nil
+	callbacks, err := loadCallbacks(dir)
+	if err != nil {
+		return Project{}, err
+	}
+
+	return Project{Dir: dir, Configuration: configuration, Callbacks: callbacks}, nil
 }
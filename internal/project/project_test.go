@@ -4,8 +4,10 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/servletcloud/Andmerada/internal/osutil"
+	"github.com/servletcloud/Andmerada/internal/placeholder"
 	"github.com/servletcloud/Andmerada/internal/project"
 	"github.com/servletcloud/Andmerada/internal/ymlutil"
 	"github.com/stretchr/testify/assert"
@@ -38,6 +40,46 @@ func TestInitialize(t *testing.T) {
 	})
 }
 
+func TestFind(t *testing.T) {
+	t.Parallel()
+
+	t.Run("finds the project in a parent directory", func(t *testing.T) {
+		t.Parallel()
+
+		projectDir := t.TempDir()
+		nestedDir := filepath.Join(projectDir, "20241225112129_create_users", "nested")
+
+		require.NoError(t, project.Initialize(projectDir))
+		require.NoError(t, os.MkdirAll(nestedDir, osutil.DirPerm0755))
+
+		dir, err := project.Find(nestedDir)
+		require.NoError(t, err)
+
+		assert.Equal(t, projectDir, dir)
+	})
+
+	t.Run("finds the project in the directory itself", func(t *testing.T) {
+		t.Parallel()
+
+		projectDir := t.TempDir()
+
+		require.NoError(t, project.Initialize(projectDir))
+
+		dir, err := project.Find(projectDir)
+		require.NoError(t, err)
+
+		assert.Equal(t, projectDir, dir)
+	})
+
+	t.Run("returns os.ErrNotExist without a project", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := project.Find(t.TempDir())
+
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
 //nolint:funlen
 func TestLoad(t *testing.T) {
 	t.Parallel()
@@ -105,6 +147,259 @@ func TestLoad(t *testing.T) {
 
 		var validationError *ymlutil.ValidationError
 
+		assert.ErrorAs(t, err, &validationError)
+	})
+	t.Run("loads a schema-qualified migrations table and the search_path", func(t *testing.T) {
+		t.Parallel()
+
+		projectDir := t.TempDir()
+		configPath := filepath.Join(projectDir, "andmerada.yml")
+		content := "migrations_table_name: ops.migrations\ncreate_schema: true\nsearch_path: [app, public]\n"
+
+		require.NoError(t, osutil.WriteFileExcl(configPath, content))
+
+		project, err := project.Load(projectDir)
+		require.NoError(t, err)
+
+		assert.Equal(t, "ops.migrations", project.Configuration.MigrationsTableName)
+		assert.True(t, project.Configuration.CreateSchema)
+		assert.Equal(t, []string{"app", "public"}, project.Configuration.SearchPath)
+	})
+
+	t.Run("loads placeholders", func(t *testing.T) {
+		t.Parallel()
+
+		projectDir := t.TempDir()
+		configPath := filepath.Join(projectDir, "andmerada.yml")
+		content := "migrations_table_name: migrations\nplaceholders:\n  app_owner: app_owner_stg\n"
+
+		require.NoError(t, osutil.WriteFileExcl(configPath, content))
+
+		project, err := project.Load(projectDir)
+		require.NoError(t, err)
+
+		assert.Equal(t, placeholder.Values{"app_owner": "app_owner_stg"}, project.Configuration.Placeholders)
+	})
+
+	t.Run("loads session defaults", func(t *testing.T) {
+		t.Parallel()
+
+		projectDir := t.TempDir()
+		configPath := filepath.Join(projectDir, "andmerada.yml")
+		content := "migrations_table_name: migrations\nsession:\n  lock_timeout: 10s\n  work_mem: 64MB\n  timeout: 30m\n"
+
+		require.NoError(t, osutil.WriteFileExcl(configPath, content))
+
+		project, err := project.Load(projectDir)
+		require.NoError(t, err)
+
+		session := project.Configuration.Session
+
+		assert.Equal(t, 10*time.Second, *session.LockTimeout)
+		assert.Equal(t, 30*time.Minute, *session.Timeout)
+		assert.Equal(t, "64MB", session.WorkMem)
+		assert.Nil(t, session.StatementTimeout)
+	})
+
+	t.Run("rejects placeholders that override built-ins", func(t *testing.T) {
+		t.Parallel()
+
+		projectDir := t.TempDir()
+		configPath := filepath.Join(projectDir, "andmerada.yml")
+		content := "migrations_table_name: migrations\nplaceholders:\n  migration_id: '1'\n"
+
+		require.NoError(t, osutil.WriteFileExcl(configPath, content))
+
+		_, err := project.Load(projectDir)
+
+		var validationError *ymlutil.ValidationError
+
+		assert.ErrorAs(t, err, &validationError)
+	})
+
+	t.Run("rejects a migrations table with more than one schema", func(t *testing.T) {
+		t.Parallel()
+
+		projectDir := t.TempDir()
+		configPath := filepath.Join(projectDir, "andmerada.yml")
+
+		require.NoError(t, osutil.WriteFileExcl(configPath, "migrations_table_name: db.ops.migrations\n"))
+
+		_, err := project.Load(projectDir)
+
+		var validationError *ymlutil.ValidationError
+
+		assert.ErrorAs(t, err, &validationError)
+	})
+}
+
+func TestLoad_Callbacks(t *testing.T) {
+	t.Parallel()
+
+	createProject := func(t *testing.T, files ...string) string {
+		t.Helper()
+
+		projectDir := t.TempDir()
+		callbacksDir := filepath.Join(projectDir, project.CallbacksDirName)
+
+		require.NoError(t, project.Initialize(projectDir))
+		require.NoError(t, os.Mkdir(callbacksDir, osutil.DirPerm0755))
+
+		for _, file := range files {
+			require.NoError(t, osutil.WriteFileExcl(filepath.Join(callbacksDir, file), "SELECT 1;"))
+		}
+
+		return projectDir
+	}
+
+	t.Run("finds the callbacks", func(t *testing.T) {
+		t.Parallel()
+
+		projectDir := createProject(t, "before_migrate.sql", "after_each.sql", "README.md")
+
+		loaded, err := project.Load(projectDir)
+		require.NoError(t, err)
+
+		callbacksDir := filepath.Join(projectDir, project.CallbacksDirName)
+		expected := project.Callbacks{
+			project.CallbackBeforeMigrate: filepath.Join(callbacksDir, "before_migrate.sql"),
+			project.CallbackAfterEach:     filepath.Join(callbacksDir, "after_each.sql"),
+		}
+		assert.Equal(t, expected, loaded.Callbacks)
+	})
+
+	t.Run("no callbacks directory", func(t *testing.T) {
+		t.Parallel()
+
+		projectDir := t.TempDir()
+		require.NoError(t, project.Initialize(projectDir))
+
+		loaded, err := project.Load(projectDir)
+		require.NoError(t, err)
+
+		assert.Empty(t, loaded.Callbacks)
+	})
+
+	t.Run("rejects an unknown callback", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := project.Load(createProject(t, "before_al.sql"))
+
+		assert.ErrorIs(t, err, project.ErrUnknownCallback)
+	})
+}
+
+//nolint:paralleltest
+func TestUseEnvironment(t *testing.T) {
+	projectDir := t.TempDir()
+	content := `migrations_table_name: migrations
+placeholders:
+  app_owner: app_owner
+  schema: app
+environments:
+  dev:
+    database_url: postgres://localhost:5432/app
+  prod:
+    database_url: postgres://app:${TEST_PROD_PASSWORD}@db:5432/app
+    migrations_table_name: ops.migrations
+    placeholders:
+      app_owner: app_owner_prod
+    dry_run: true
+    fail_on_warning: true
+    protected: true
+`
+
+	require.NoError(t, osutil.WriteFileExcl(filepath.Join(projectDir, "andmerada.yml"), content))
+
+	t.Run("applies the environment", func(t *testing.T) {
+		t.Setenv("TEST_PROD_PASSWORD", "secret")
+
+		loaded, err := project.Load(projectDir)
+		require.NoError(t, err)
+
+		environment, err := loaded.UseEnvironment("prod")
+		require.NoError(t, err)
+
+		assert.Equal(t, "prod", environment.Name)
+		assert.Equal(t, "postgres://app:secret@db:5432/app", environment.DatabaseURL)
+		assert.True(t, environment.Protected)
+		assert.True(t, *environment.DryRun)
+		assert.Nil(t, environment.AllowDrift)
+		assert.Equal(t, "ops.migrations", loaded.Configuration.MigrationsTableName)
+		assert.True(t, loaded.Configuration.FailOnWarning)
+
+		expected := placeholder.Values{"app_owner": "app_owner_prod", "schema": "app"}
+		assert.Equal(t, expected, loaded.Configuration.Placeholders)
+	})
+
+	t.Run("keeps the project configuration", func(t *testing.T) {
+		loaded, err := project.Load(projectDir)
+		require.NoError(t, err)
+
+		environment, err := loaded.UseEnvironment("dev")
+		require.NoError(t, err)
+
+		assert.False(t, environment.Protected)
+		assert.Equal(t, "migrations", loaded.Configuration.MigrationsTableName)
+		assert.False(t, loaded.Configuration.FailOnWarning)
+	})
+
+	t.Run("fails on an undefined environment variable", func(t *testing.T) {
+		loaded, err := project.Load(projectDir)
+		require.NoError(t, err)
+
+		_, err = loaded.UseEnvironment("prod")
+		assert.ErrorIs(t, err, project.ErrUndefinedEnvVariable)
+	})
+
+	t.Run("fails on an unknown environment", func(t *testing.T) {
+		loaded, err := project.Load(projectDir)
+		require.NoError(t, err)
+
+		_, err = loaded.UseEnvironment("staging")
+		assert.ErrorIs(t, err, project.ErrUnknownEnvironment)
+	})
+}
+
+func TestLoad_LocalOverlay(t *testing.T) {
+	t.Parallel()
+
+	createProject := func(t *testing.T, overlay string) string {
+		t.Helper()
+
+		projectDir := t.TempDir()
+		content := "migrations_table_name: migrations\nsearch_path: [app, public]\n" +
+			"environments:\n  dev:\n    database_url: postgres://localhost/app\n    filter: id > 0\n"
+
+		require.NoError(t, osutil.WriteFileExcl(filepath.Join(projectDir, "andmerada.yml"), content))
+		require.NoError(t, osutil.WriteFileExcl(filepath.Join(projectDir, "andmerada.local.yml"), overlay))
+
+		return projectDir
+	}
+
+	t.Run("merges the overlay on top", func(t *testing.T) {
+		t.Parallel()
+
+		overlay := "search_path: [dev]\nenvironments:\n  dev:\n    database_url: postgres://me@localhost/app\n"
+
+		loaded, err := project.Load(createProject(t, overlay))
+		require.NoError(t, err)
+
+		configuration := loaded.Configuration
+
+		assert.Equal(t, "migrations", configuration.MigrationsTableName)
+		assert.Equal(t, []string{"dev"}, configuration.SearchPath)
+		assert.Equal(t, "postgres://me@localhost/app", configuration.Environments["dev"].DatabaseURL)
+		assert.Equal(t, "id > 0", configuration.Environments["dev"].Filter)
+	})
+
+	t.Run("validates the merged configuration", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := project.Load(createProject(t, "environments:\n  dev:\n    unknown: true\n"))
+
+		var validationError *ymlutil.ValidationError
+
 		assert.ErrorAs(t, err, &validationError)
 	})
 }
@@ -49,8 +49,8 @@ func MsgInitCompleted() string {
 	return msgInitCompleted
 }
 
-func MsgErrProjectExists() string {
-	return msgErrProjectExists
+func MsgErrProjectExists(path string) string {
+	return strings.ReplaceAll(msgErrProjectExists, "{{path}}", path)
 }
 
 func MsgMigrationCreated(dir string) string {
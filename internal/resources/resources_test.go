@@ -55,3 +55,12 @@ func TestMsgMigrationCreated(t *testing.T) {
 	assert.Contains(t, content, "Migration successfully created at 20241225112129_create_users")
 	tests.AssertPlaceholdersResolved(t, content)
 }
+
+func TestMsgErrProjectExists(t *testing.T) {
+	t.Parallel()
+
+	content := resources.MsgErrProjectExists("/repo/db/migrations/andmerada.yml")
+
+	assert.Contains(t, content, "The file '/repo/db/migrations/andmerada.yml' already exists.")
+	tests.AssertPlaceholdersResolved(t, content)
+}
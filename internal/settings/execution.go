@@ -0,0 +1,18 @@
+package settings
+
+// ExecutionMode controls how Andmerada sends migration SQL to the database.
+type ExecutionMode string
+
+const (
+	// ExecutionModeBatch sends the whole file as one multi-statement query.
+	ExecutionModeBatch ExecutionMode = "batch"
+	// ExecutionModeStatement sends the statements one by one and logs the command tag and duration of each.
+	ExecutionModeStatement ExecutionMode = "statement"
+
+	DefaultExecutionMode = ExecutionModeBatch
+)
+
+// ResolveExecutionMode returns the last non-empty mode of the given layers, or DefaultExecutionMode.
+func ResolveExecutionMode(layers ...ExecutionMode) ExecutionMode {
+	return resolveLast(DefaultExecutionMode, layers...)
+}
@@ -0,0 +1,23 @@
+package settings
+
+// IsolationMode controls how 'andmerada migrate' cleans up the session state that a migration leaves
+// on the shared connection, e.g. a plain SET, SET ROLE, temporary tables or prepared statements.
+type IsolationMode string
+
+const (
+	// IsolationNone keeps the session as the migration left it.
+	IsolationNone IsolationMode = "none"
+	// IsolationReset runs RESET ALL, which resets run-time parameters including the role.
+	IsolationReset IsolationMode = "reset"
+	// IsolationDiscard runs DISCARD ALL, which also drops temporary tables and prepared statements.
+	IsolationDiscard IsolationMode = "discard"
+	// IsolationReconnect opens a fresh connection for the next migration.
+	IsolationReconnect IsolationMode = "reconnect"
+
+	DefaultIsolationMode = IsolationNone
+)
+
+// ResolveIsolationMode returns the last non-empty mode of the given layers, or DefaultIsolationMode.
+func ResolveIsolationMode(layers ...IsolationMode) IsolationMode {
+	return resolveLast(DefaultIsolationMode, layers...)
+}
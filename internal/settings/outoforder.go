@@ -0,0 +1,27 @@
+package settings
+
+// OutOfOrderPolicy controls what happens to a pending migration whose ID is older
+// than the latest applied one, e.g. after a long-lived branch is merged.
+type OutOfOrderPolicy string
+
+const (
+	OutOfOrderAllow OutOfOrderPolicy = "allow"
+	OutOfOrderWarn  OutOfOrderPolicy = "warn"
+	OutOfOrderFail  OutOfOrderPolicy = "fail"
+
+	DefaultOutOfOrderPolicy = OutOfOrderWarn
+)
+
+func (policy OutOfOrderPolicy) IsValid() bool {
+	switch policy {
+	case OutOfOrderAllow, OutOfOrderWarn, OutOfOrderFail:
+		return true
+	}
+
+	return false
+}
+
+// ResolveOutOfOrderPolicy returns the last non-empty policy of the given layers, or DefaultOutOfOrderPolicy.
+func ResolveOutOfOrderPolicy(layers ...OutOfOrderPolicy) OutOfOrderPolicy {
+	return resolveLast(DefaultOutOfOrderPolicy, layers...)
+}
@@ -0,0 +1,18 @@
+package settings_test
+
+import (
+	"testing"
+
+	"github.com/servletcloud/Andmerada/internal/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutOfOrderPolicy_IsValid(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, settings.OutOfOrderAllow.IsValid())
+	assert.True(t, settings.OutOfOrderWarn.IsValid())
+	assert.True(t, settings.OutOfOrderFail.IsValid())
+	assert.False(t, settings.OutOfOrderPolicy("").IsValid())
+	assert.False(t, settings.OutOfOrderPolicy("ignore").IsValid())
+}
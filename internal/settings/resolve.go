@@ -0,0 +1,15 @@
+package settings
+
+// resolveLast returns the last non-empty value of the given layers, e.g. migration.yml over andmerada.yml,
+// or fallback when every layer is empty.
+func resolveLast[T ~string](fallback T, layers ...T) T {
+	result := fallback
+
+	for _, layer := range layers {
+		if layer != "" {
+			result = layer
+		}
+	}
+
+	return result
+}
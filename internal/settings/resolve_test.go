@@ -0,0 +1,16 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveLast(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, TransactionModeNone, resolveLast(TransactionModeNone))
+	assert.Equal(t, TransactionModeNone, resolveLast(TransactionModeNone, "", ""))
+	assert.Equal(t, TransactionModeAuto, resolveLast(TransactionModeNone, TransactionModeAuto, ""))
+	assert.Equal(t, TransactionModeNone, resolveLast(TransactionModeNone, TransactionModeAuto, TransactionModeNone))
+}
@@ -0,0 +1,94 @@
+package settings
+
+import (
+	"slices"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+)
+
+const (
+	defaultMaxAttempts    = 1
+	defaultInitialBackoff = time.Second
+	defaultMaxBackoff     = 30 * time.Second
+	defaultMultiplier     = 2.0
+)
+
+type Retry struct {
+	MaxAttempts           *int           `yaml:"max_attempts,omitempty"`
+	InitialBackoff        *time.Duration `yaml:"initial_backoff,omitempty"`
+	MaxBackoff            *time.Duration `yaml:"max_backoff,omitempty"`
+	Multiplier            *float64       `yaml:"multiplier,omitempty"`
+	SQLStates             []string       `yaml:"sqlstates,omitempty"`
+	RetryOnConnectionLoss *bool          `yaml:"retry_on_connection_loss,omitempty"`
+}
+
+type RetryPolicy struct {
+	MaxAttempts           int
+	InitialBackoff        time.Duration
+	MaxBackoff            time.Duration
+	Multiplier            float64
+	SQLStates             []string
+	RetryOnConnectionLoss bool
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    defaultMaxAttempts,
+		InitialBackoff: defaultInitialBackoff,
+		MaxBackoff:     defaultMaxBackoff,
+		Multiplier:     defaultMultiplier,
+		SQLStates: []string{
+			pgerrcode.LockNotAvailable,
+			pgerrcode.DeadlockDetected,
+			pgerrcode.SerializationFailure,
+		},
+		// A connection lost during COMMIT leaves the outcome of the transaction unknown,
+		// so retrying it is opt-in.
+		RetryOnConnectionLoss: false,
+	}
+}
+
+// ResolveRetryPolicy applies the given layers on top of the defaults.
+// Later layers take precedence, e.g. migration.yml over andmerada.yml.
+func ResolveRetryPolicy(layers ...Retry) RetryPolicy {
+	policy := DefaultRetryPolicy()
+
+	for _, layer := range layers {
+		override(&policy.MaxAttempts, layer.MaxAttempts)
+		override(&policy.InitialBackoff, layer.InitialBackoff)
+		override(&policy.MaxBackoff, layer.MaxBackoff)
+		override(&policy.Multiplier, layer.Multiplier)
+		override(&policy.RetryOnConnectionLoss, layer.RetryOnConnectionLoss)
+
+		if layer.SQLStates != nil {
+			policy.SQLStates = layer.SQLStates
+		}
+	}
+
+	return policy
+}
+
+func (policy *RetryPolicy) Backoff(attempt int) time.Duration {
+	backoff := float64(policy.InitialBackoff)
+
+	for range attempt - 1 {
+		backoff *= policy.Multiplier
+
+		if backoff >= float64(policy.MaxBackoff) {
+			return policy.MaxBackoff
+		}
+	}
+
+	return min(time.Duration(backoff), policy.MaxBackoff)
+}
+
+func (policy *RetryPolicy) IsRetryableSQLState(code string) bool {
+	return slices.Contains(policy.SQLStates, code)
+}
+
+func override[T any](target *T, value *T) {
+	if value != nil {
+		*target = *value
+	}
+}
@@ -0,0 +1,65 @@
+package settings_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/servletcloud/Andmerada/internal/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveRetryPolicy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("defaults do not retry", func(t *testing.T) {
+		t.Parallel()
+
+		policy := settings.ResolveRetryPolicy()
+
+		assert.Equal(t, 1, policy.MaxAttempts)
+		assert.True(t, policy.IsRetryableSQLState("55P03"))
+		assert.True(t, policy.IsRetryableSQLState("40P01"))
+		assert.True(t, policy.IsRetryableSQLState("40001"))
+		assert.False(t, policy.IsRetryableSQLState("42P01"))
+	})
+
+	t.Run("later layers override earlier ones field by field", func(t *testing.T) {
+		t.Parallel()
+
+		projectAttempts, migrationAttempts := 3, 5
+		backoff := 2 * time.Second
+
+		projectLayer := settings.Retry{ //nolint:exhaustruct
+			MaxAttempts:    &projectAttempts,
+			InitialBackoff: &backoff,
+			SQLStates:      []string{"55P03"},
+		}
+		migrationLayer := settings.Retry{MaxAttempts: &migrationAttempts} //nolint:exhaustruct
+
+		policy := settings.ResolveRetryPolicy(projectLayer, migrationLayer)
+
+		assert.Equal(t, 5, policy.MaxAttempts)
+		assert.Equal(t, 2*time.Second, policy.InitialBackoff)
+		assert.Equal(t, []string{"55P03"}, policy.SQLStates)
+		assert.False(t, policy.RetryOnConnectionLoss)
+	})
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	t.Parallel()
+
+	policy := settings.RetryPolicy{
+		MaxAttempts:           10,
+		InitialBackoff:        time.Second,
+		MaxBackoff:            5 * time.Second,
+		Multiplier:            2,
+		SQLStates:             nil,
+		RetryOnConnectionLoss: true,
+	}
+
+	assert.Equal(t, time.Second, policy.Backoff(1))
+	assert.Equal(t, 2*time.Second, policy.Backoff(2))
+	assert.Equal(t, 4*time.Second, policy.Backoff(3))
+	assert.Equal(t, 5*time.Second, policy.Backoff(4))
+	assert.Equal(t, 5*time.Second, policy.Backoff(100))
+}
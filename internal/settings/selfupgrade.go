@@ -0,0 +1,19 @@
+package settings
+
+// SelfUpgradeMode controls whether 'andmerada migrate' upgrades Andmerada's own bookkeeping tables
+// to the version that the running binary maintains.
+type SelfUpgradeMode string
+
+const (
+	// SelfUpgradeAuto applies the pending upgrade steps before scanning applied migrations.
+	SelfUpgradeAuto SelfUpgradeMode = "auto"
+	// SelfUpgradeManual refuses to migrate until 'andmerada self-upgrade' is run, e.g. by a DBA.
+	SelfUpgradeManual SelfUpgradeMode = "manual"
+
+	DefaultSelfUpgradeMode = SelfUpgradeAuto
+)
+
+// ResolveSelfUpgradeMode returns the last non-empty mode of the given layers, or DefaultSelfUpgradeMode.
+func ResolveSelfUpgradeMode(layers ...SelfUpgradeMode) SelfUpgradeMode {
+	return resolveLast(DefaultSelfUpgradeMode, layers...)
+}
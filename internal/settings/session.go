@@ -0,0 +1,44 @@
+package settings
+
+import "time"
+
+// Session holds the settings of the database session in which a migration runs.
+// Unset fields keep the value of the session.
+type Session struct {
+	LockTimeout                     *time.Duration `yaml:"lock_timeout,omitempty"`
+	StatementTimeout                *time.Duration `yaml:"statement_timeout,omitempty"`
+	IdleInTransactionSessionTimeout *time.Duration `yaml:"idle_in_transaction_session_timeout,omitempty"`
+	WorkMem                         string         `yaml:"work_mem,omitempty"`
+	SearchPath                      []string       `yaml:"search_path,omitempty"`
+	// Timeout bounds the wall-clock time of one attempt to apply a migration.
+	Timeout *time.Duration `yaml:"timeout,omitempty"`
+}
+
+// ResolveSession applies the given layers on top of each other.
+// Later layers take precedence, e.g. migration.yml over andmerada.yml.
+func ResolveSession(layers ...Session) Session {
+	var session Session
+
+	for _, layer := range layers {
+		overridePointer(&session.LockTimeout, layer.LockTimeout)
+		overridePointer(&session.StatementTimeout, layer.StatementTimeout)
+		overridePointer(&session.IdleInTransactionSessionTimeout, layer.IdleInTransactionSessionTimeout)
+		overridePointer(&session.Timeout, layer.Timeout)
+
+		if layer.WorkMem != "" {
+			session.WorkMem = layer.WorkMem
+		}
+
+		if layer.SearchPath != nil {
+			session.SearchPath = layer.SearchPath
+		}
+	}
+
+	return session
+}
+
+func overridePointer[T any](target **T, value *T) {
+	if value != nil {
+		*target = value
+	}
+}
@@ -0,0 +1,49 @@
+package settings_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/servletcloud/Andmerada/internal/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveSession(t *testing.T) {
+	t.Parallel()
+
+	t.Run("defaults keep the session as is", func(t *testing.T) {
+		t.Parallel()
+
+		session := settings.ResolveSession()
+
+		assert.Nil(t, session.LockTimeout)
+		assert.Nil(t, session.Timeout)
+		assert.Empty(t, session.WorkMem)
+		assert.Nil(t, session.SearchPath)
+	})
+
+	t.Run("later layers override earlier ones field by field", func(t *testing.T) {
+		t.Parallel()
+
+		projectLockTimeout, migrationLockTimeout := 10*time.Second, 30*time.Second
+		statementTimeout := 5 * time.Minute
+
+		projectLayer := settings.Session{ //nolint:exhaustruct
+			LockTimeout:      &projectLockTimeout,
+			StatementTimeout: &statementTimeout,
+			WorkMem:          "64MB",
+		}
+		migrationLayer := settings.Session{ //nolint:exhaustruct
+			LockTimeout: &migrationLockTimeout,
+			SearchPath:  []string{"app"},
+		}
+
+		session := settings.ResolveSession(projectLayer, migrationLayer)
+
+		assert.Equal(t, 30*time.Second, *session.LockTimeout)
+		assert.Equal(t, 5*time.Minute, *session.StatementTimeout)
+		assert.Equal(t, "64MB", session.WorkMem)
+		assert.Equal(t, []string{"app"}, session.SearchPath)
+		assert.Nil(t, session.IdleInTransactionSessionTimeout)
+	})
+}
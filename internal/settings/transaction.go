@@ -0,0 +1,18 @@
+package settings
+
+// TransactionMode controls whether Andmerada wraps migration SQL in a transaction.
+type TransactionMode string
+
+const (
+	// TransactionModeAuto wraps the SQL in BEGIN ... COMMIT. The SQL must not control transactions itself.
+	TransactionModeAuto TransactionMode = "auto"
+	// TransactionModeNone runs the SQL as is, e.g. for CREATE INDEX CONCURRENTLY.
+	TransactionModeNone TransactionMode = "none"
+
+	DefaultTransactionMode = TransactionModeNone
+)
+
+// ResolveTransactionMode returns the last non-empty mode of the given layers, or DefaultTransactionMode.
+func ResolveTransactionMode(layers ...TransactionMode) TransactionMode {
+	return resolveLast(DefaultTransactionMode, layers...)
+}
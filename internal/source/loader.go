@@ -35,6 +35,15 @@ func (loader *Loader) ValidateSource(dir string, out *Source) error {
 	})
 }
 
+func (loader *Loader) LoadConfiguration(dir string, out *Configuration) error {
+	return loader.loadConfiguration(dir, out)
+}
+
+// LoadSQLFile reads the SQL file at path. Like the SQL files of migrations, it must not exceed MaxSQLFileSize.
+func (loader *Loader) LoadSQLFile(path string) (string, error) {
+	return loader.loadSQLFile(filepath.Dir(path), filepath.Base(path), os.ReadFile)
+}
+
 func (loader *Loader) loadSource(dir string, out *Source, readFunc readFileFunc) error {
 	if err := loader.loadConfiguration(dir, &out.Configuration); err != nil {
 		return err
@@ -47,6 +56,9 @@ func (loader *Loader) loadConfiguration(dir string, out *Configuration) error {
 	path := filepath.Join(dir, MigrationYmlFilename)
 	schema := schema.GetMigrationSchema()
 
+	// Optional fields absent from the file must not keep values of a previously loaded migration.
+	*out = Configuration{} //nolint:exhaustruct
+
 	return ymlutil.LoadFromFile(path, schema, out) //nolint:wrapcheck
 }
 
@@ -60,6 +72,7 @@ func (loader *Loader) loadSQLFiles(dir string, out *Source, readFunc readFileFun
 	}
 
 	out.UpSQL = upSQL
+	out.DownSQL = ""
 
 	if config.Down.Block {
 		return nil
@@ -1,6 +1,9 @@
 package source_test
 
 import (
+	"bytes"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/servletcloud/Andmerada/internal/resources"
@@ -37,6 +40,24 @@ func TestLoadSource(t *testing.T) {
 		assert.Equal(t, "down.sql", config.Down.File)
 		assert.False(t, config.Down.Block)
 	})
+
+	t.Run("Test does not keep down SQL of a previous source when down is blocked", func(t *testing.T) {
+		t.Parallel()
+
+		blockedDir := tests.CreateSource(t, dir, "Blocked", "20241225112130").FullPath
+		ymlPath := filepath.Join(blockedDir, source.MigrationYmlFilename)
+		content, err := os.ReadFile(ymlPath)
+		require.NoError(t, err)
+		blockedContent := bytes.Replace(content, []byte("block: false"), []byte("block: true"), 1)
+		require.NoError(t, os.WriteFile(ymlPath, blockedContent, 0600))
+
+		src := source.Source{} //nolint:exhaustruct
+		require.NoError(t, loader.LoadSource(sourceDir, &src))
+		require.NoError(t, loader.LoadSource(blockedDir, &src))
+
+		assert.True(t, src.Configuration.Down.Block)
+		assert.Empty(t, src.DownSQL)
+	})
 }
 
 func TestLoadSource_ValidateSource(t *testing.T) {
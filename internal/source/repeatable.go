@@ -0,0 +1,88 @@
+package source
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	// RepeatableDirName is the directory of repeatable migrations: SQL files without an ID that are
+	// re-applied whenever their content changes, e.g. definitions of views, functions and triggers.
+	RepeatableDirName = "repeatable"
+
+	// MinRepeatableID is the lowest ID under which a repeatable migration is recorded in the migrations
+	// table. It is far above MaxMigrationID, so repeatable rows never mix with versioned ones.
+	MinRepeatableID = ID(100_000_000_000_000_000)
+
+	repeatableExt = ".sql"
+)
+
+// ScanRepeatables returns the names of the repeatable migrations, relative to the project dir
+// and sorted in the order they are applied, e.g. "repeatable/users_view.sql".
+func ScanRepeatables(projectDir string) ([]string, error) {
+	dir := filepath.Join(projectDir, RepeatableDirName)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("cannot read directory %v because: %w", dir, err)
+	}
+
+	var names []string
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), repeatableExt) {
+			continue
+		}
+
+		names = append(names, RepeatableDirName+"/"+entry.Name())
+	}
+
+	slices.Sort(names)
+
+	return names, nil
+}
+
+// RepeatableID derives a stable ID of the repeatable migration from its name. The ID stays
+// within the range of BIGINT, the type of the ID column in the migrations table.
+func RepeatableID(name string) ID {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(name))
+
+	span := uint64(math.MaxInt64) - uint64(MinRepeatableID)
+
+	return MinRepeatableID + ID(hash.Sum64()%span)
+}
+
+func (id ID) IsRepeatable() bool {
+	return id >= MinRepeatableID
+}
+
+// LoadRepeatable loads the repeatable migration with the given name as a source whose up SQL
+// is the content of the file. Repeatable migrations have no down SQL.
+func (loader *Loader) LoadRepeatable(projectDir, name string, out *Source) error {
+	*out = Source{} //nolint:exhaustruct
+
+	upSQL, err := loader.loadSQLFile(projectDir, filepath.FromSlash(name), os.ReadFile)
+	if err != nil {
+		return err
+	}
+
+	out.Configuration.Name = name
+	out.Configuration.Up.File = filepath.Base(name)
+	out.Configuration.Down.Block = true
+	out.Configuration.Down.BlockReason = "Repeatable migrations are re-applied instead of rolled back."
+	out.Configuration.Meta = map[string]any{"repeatable": true}
+	out.UpSQL = upSQL
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package source_test
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/servletcloud/Andmerada/internal/osutil"
+	"github.com/servletcloud/Andmerada/internal/source"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanRepeatables(t *testing.T) {
+	t.Parallel()
+
+	t.Run("lists SQL files in name order", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		repeatableDir := filepath.Join(dir, source.RepeatableDirName)
+
+		require.NoError(t, os.Mkdir(repeatableDir, osutil.DirPerm0755))
+		require.NoError(t, os.Mkdir(filepath.Join(repeatableDir, "nested.sql"), osutil.DirPerm0755))
+
+		for _, name := range []string{"b_view.sql", "a_function.sql", "README.md"} {
+			require.NoError(t, os.WriteFile(filepath.Join(repeatableDir, name), nil, osutil.FilePerm0644))
+		}
+
+		names, err := source.ScanRepeatables(dir)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"repeatable/a_function.sql", "repeatable/b_view.sql"}, names)
+	})
+
+	t.Run("no repeatable directory", func(t *testing.T) {
+		t.Parallel()
+
+		names, err := source.ScanRepeatables(t.TempDir())
+		require.NoError(t, err)
+
+		assert.Empty(t, names)
+	})
+}
+
+func TestRepeatableID(t *testing.T) {
+	t.Parallel()
+
+	id := source.RepeatableID("repeatable/users_view.sql")
+
+	assert.Equal(t, id, source.RepeatableID("repeatable/users_view.sql"))
+	assert.NotEqual(t, id, source.RepeatableID("repeatable/orders_view.sql"))
+	assert.True(t, id.IsRepeatable())
+	assert.LessOrEqual(t, uint64(id), uint64(math.MaxInt64))
+	assert.False(t, source.MaxMigrationID.IsRepeatable())
+}
+
+func TestLoader_LoadRepeatable(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	repeatableDir := filepath.Join(dir, source.RepeatableDirName)
+	sql := "CREATE OR REPLACE VIEW users_view AS SELECT 1;"
+
+	require.NoError(t, os.Mkdir(repeatableDir, osutil.DirPerm0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repeatableDir, "users_view.sql"), []byte(sql), osutil.FilePerm0644))
+
+	t.Run("loads the file as up SQL", func(t *testing.T) {
+		t.Parallel()
+
+		loader := source.Loader{MaxSQLFileSize: 1024}
+		src := source.Source{} //nolint:exhaustruct
+
+		require.NoError(t, loader.LoadRepeatable(dir, "repeatable/users_view.sql", &src))
+
+		assert.Equal(t, "repeatable/users_view.sql", src.Configuration.Name)
+		assert.Equal(t, sql, src.UpSQL)
+		assert.Empty(t, src.DownSQL)
+		assert.True(t, src.Configuration.Down.Block)
+	})
+
+	t.Run("fails on a too big file", func(t *testing.T) {
+		t.Parallel()
+
+		loader := source.Loader{MaxSQLFileSize: 10}
+		src := source.Source{} //nolint:exhaustruct
+
+		var tooBigErr *source.FileTooBigError
+
+		require.ErrorAs(t, loader.LoadRepeatable(dir, "repeatable/users_view.sql", &src), &tooBigErr)
+	})
+}
@@ -2,6 +2,8 @@ package source
 
 import (
 	"errors"
+
+	"github.com/servletcloud/Andmerada/internal/settings"
 )
 
 type CreateSourceResult struct {
@@ -23,6 +25,11 @@ type Configuration struct {
 		BlockReason string `yaml:"block_reason"`
 	} `yaml:"down"`
 
+	Transaction settings.TransactionMode `yaml:"transaction,omitempty"`
+	Execution   settings.ExecutionMode   `yaml:"execution,omitempty"`
+	Retry       settings.Retry           `yaml:"retry,omitempty"`
+	Session     settings.Session         `yaml:"session,omitempty"`
+
 	Meta map[string]any `yaml:"meta"`
 }
 
@@ -0,0 +1,30 @@
+package sqlparse
+
+import "strings"
+
+// Rewrite returns the SQL with the text of statements replaced by the result of replace.
+// Statements for which replace returns false, comments and whitespace are kept as is.
+// The statements must come from Split of the same SQL.
+func Rewrite(sql string, statements []Statement, replace func(*Statement) (string, bool)) string {
+	var sb strings.Builder
+
+	last := 0
+
+	for i := range statements {
+		statement := &statements[i]
+
+		replacement, ok := replace(statement)
+		if !ok {
+			continue
+		}
+
+		sb.WriteString(sql[last:statement.Start])
+		sb.WriteString(replacement)
+
+		last = statement.Start + len(statement.Text)
+	}
+
+	sb.WriteString(sql[last:])
+
+	return sb.String()
+}
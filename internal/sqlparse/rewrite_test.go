@@ -0,0 +1,33 @@
+package sqlparse_test
+
+import (
+	"testing"
+
+	"github.com/servletcloud/Andmerada/internal/sqlparse"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRewrite(t *testing.T) {
+	t.Parallel()
+
+	sql := "-- header\nBEGIN;\nINSERT INTO t VALUES ('COMMIT;');\n/* tail */ COMMIT  ;\n"
+
+	rewritten := sqlparse.Rewrite(sql, sqlparse.Split(sql), func(statement *sqlparse.Statement) (string, bool) {
+		switch statement.Kind() { //nolint:exhaustive
+		case sqlparse.KindBegin:
+			return "SAVEPOINT s;", true
+		case sqlparse.KindCommit:
+			return "RELEASE SAVEPOINT s;", true
+		}
+
+		return "", false
+	})
+
+	expected := "-- header\nSAVEPOINT s;\nINSERT INTO t VALUES ('COMMIT;');\n/* tail */ RELEASE SAVEPOINT s;\n"
+	assert.Equal(t, expected, rewritten)
+
+	unchanged := sqlparse.Rewrite(sql, sqlparse.Split(sql), func(*sqlparse.Statement) (string, bool) {
+		return "", false
+	})
+	assert.Equal(t, sql, unchanged)
+}
@@ -0,0 +1,73 @@
+package sqlparse
+
+type Script struct {
+	Statements []Statement
+}
+
+func Parse(sql string) Script {
+	return Script{Statements: Split(sql)}
+}
+
+func (script *Script) HasTransactionControl() bool {
+	_, found := script.FirstTransactionControl()
+
+	return found
+}
+
+func (script *Script) HasNonTransactional() bool {
+	_, found := script.FirstNonTransactional()
+
+	return found
+}
+
+func (script *Script) FirstTransactionControl() (Statement, bool) {
+	return script.first((*Statement).IsTransactionControl)
+}
+
+func (script *Script) FirstNonTransactional() (Statement, bool) {
+	return script.first((*Statement).IsNonTransactional)
+}
+
+func (script *Script) FirstSessionSet() (Statement, bool) {
+	return script.first((*Statement).IsSessionSet)
+}
+
+// IsSingleTransaction reports whether a failure of the script leaves no
+// changes behind: the script is either wrapped in one BEGIN ... COMMIT block,
+// or has no transaction control at all and therefore runs in the implicit
+// transaction of a multi-statement simple query.
+func (script *Script) IsSingleTransaction() bool {
+	if script.HasNonTransactional() {
+		return false
+	}
+
+	statements := script.Statements
+	count := len(statements)
+
+	if !script.HasTransactionControl() {
+		return true
+	}
+
+	if count < 2 || statements[0].Kind() != KindBegin || statements[count-1].Kind() != KindCommit {
+		return false
+	}
+
+	for i := 1; i < count-1; i++ {
+		switch statements[i].Kind() { //nolint:exhaustive
+		case KindBegin, KindCommit, KindRollback, KindTwoPhaseCommit:
+			return false
+		}
+	}
+
+	return true
+}
+
+func (script *Script) first(predicate func(*Statement) bool) (Statement, bool) {
+	for i := range script.Statements {
+		if predicate(&script.Statements[i]) {
+			return script.Statements[i], true
+		}
+	}
+
+	return Statement{}, false //nolint:exhaustruct
+}
@@ -0,0 +1,47 @@
+package sqlparse_test
+
+import (
+	"testing"
+
+	"github.com/servletcloud/Andmerada/internal/sqlparse"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScript_IsSingleTransaction(t *testing.T) {
+	t.Parallel()
+
+	expected := map[string]bool{
+		"": true,
+		"CREATE TABLE a (id INT); CREATE TABLE b (id INT);":                true,
+		"-- comment\nBEGIN; CREATE TABLE a (id INT); COMMIT;":              true,
+		"BEGIN; SAVEPOINT s; SELECT 1; ROLLBACK TO SAVEPOINT s; COMMIT;":   true,
+		"BEGIN; CREATE TABLE a (id INT);":                                  false,
+		"CREATE TABLE a (id INT); BEGIN; CREATE TABLE b (id INT); COMMIT;": false,
+		"BEGIN; SELECT 1; COMMIT; BEGIN; SELECT 2; COMMIT;":                false,
+		"CREATE INDEX CONCURRENTLY idx ON a (id);":                         false,
+	}
+
+	for sql, singleTransaction := range expected {
+		script := sqlparse.Parse(sql)
+
+		assert.Equal(t, singleTransaction, script.IsSingleTransaction(), sql)
+	}
+}
+
+func TestScript_FirstTransactionControl(t *testing.T) {
+	t.Parallel()
+
+	script := sqlparse.Parse("CREATE TABLE a (id INT);\n\nCOMMIT;\nBEGIN;")
+
+	statement, found := script.FirstTransactionControl()
+
+	assert.True(t, found)
+	assert.Equal(t, "COMMIT;", statement.Text)
+	assert.Equal(t, 3, statement.Line)
+
+	script = sqlparse.Parse("CREATE TABLE a (id INT);")
+
+	_, found = script.FirstTransactionControl()
+
+	assert.False(t, found)
+}
@@ -0,0 +1,257 @@
+package sqlparse
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type Statement struct {
+	Text  string
+	Start int
+	Line  int
+	Words []string
+}
+
+type splitter struct {
+	sql string
+	pos int
+
+	statements []Statement
+	start      int
+	words      []string
+	blockDepth int
+}
+
+func Split(sql string) []Statement {
+	splitter := &splitter{sql: sql, pos: 0, statements: nil, start: -1, words: nil, blockDepth: 0}
+
+	splitter.split()
+
+	return splitter.statements
+}
+
+func (s *splitter) split() {
+	for s.pos < len(s.sql) {
+		ch := s.sql[s.pos]
+
+		switch {
+		case ch == '-' && s.peek(1) == '-':
+			s.skipLineComment()
+		case ch == '/' && s.peek(1) == '*':
+			s.skipBlockComment()
+		case ch == ';':
+			s.pos++
+			s.endStatement()
+		case isSpace(ch):
+			s.pos++
+		default:
+			s.markStart()
+			s.scanToken()
+		}
+	}
+
+	s.endStatement()
+}
+
+func (s *splitter) scanToken() {
+	ch := s.sql[s.pos]
+
+	switch {
+	case ch == '\'':
+		s.skipQuoted('\'', false)
+	case ch == '"':
+		s.skipQuoted('"', false)
+	case ch == '$' && s.dollarTagLength() > 0:
+		s.skipDollarQuoted()
+	case isIdentStart(s.sql, s.pos):
+		s.scanWord()
+	default:
+		_, size := utf8.DecodeRuneInString(s.sql[s.pos:])
+		s.pos += size
+	}
+}
+
+func (s *splitter) scanWord() {
+	begin := s.pos
+
+	for s.pos < len(s.sql) && isIdentPart(s.sql, s.pos) {
+		_, size := utf8.DecodeRuneInString(s.sql[s.pos:])
+		s.pos += size
+	}
+
+	word := strings.ToUpper(s.sql[begin:s.pos])
+
+	if word == "E" && s.peek(0) == '\'' {
+		s.skipQuoted('\'', true)
+
+		return
+	}
+
+	s.words = append(s.words, word)
+	s.trackBlock(word)
+}
+
+// trackBlock keeps semicolons inside SQL-standard function bodies
+// (BEGIN ATOMIC ... END) from splitting the statement.
+func (s *splitter) trackBlock(word string) {
+	if !s.isRoutineDefinition() {
+		return
+	}
+
+	switch {
+	case word == "BEGIN":
+		s.blockDepth++
+	case word == "CASE" && s.blockDepth > 0:
+		s.blockDepth++
+	case word == "END" && s.blockDepth > 0:
+		s.blockDepth--
+	}
+}
+
+func (s *splitter) isRoutineDefinition() bool {
+	words := s.words
+
+	if len(words) < 2 || words[0] != "CREATE" {
+		return false
+	}
+
+	if len(words) >= 4 && words[1] == "OR" && words[2] == "REPLACE" {
+		return words[3] == "FUNCTION" || words[3] == "PROCEDURE"
+	}
+
+	return words[1] == "FUNCTION" || words[1] == "PROCEDURE"
+}
+
+func (s *splitter) skipQuoted(quote byte, backslashEscapes bool) {
+	for s.pos++; s.pos < len(s.sql) && s.sql[s.pos] != quote; s.pos++ {
+		if backslashEscapes && s.sql[s.pos] == '\\' {
+			s.pos++
+		}
+	}
+
+	s.pos++
+
+	if s.pos < len(s.sql) && s.sql[s.pos] == quote {
+		s.skipQuoted(quote, backslashEscapes)
+	}
+}
+
+func (s *splitter) dollarTagLength() int {
+	if s.pos > 0 && isIdentPart(s.sql, s.pos-1) {
+		return 0
+	}
+
+	for i := s.pos + 1; i < len(s.sql); {
+		if s.sql[i] == '$' {
+			return i - s.pos + 1
+		}
+
+		r, size := utf8.DecodeRuneInString(s.sql[i:])
+
+		if unicode.IsDigit(r) && i == s.pos+1 {
+			return 0
+		}
+
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return 0
+		}
+
+		i += size
+	}
+
+	return 0
+}
+
+func (s *splitter) skipDollarQuoted() {
+	tag := s.sql[s.pos : s.pos+s.dollarTagLength()]
+	s.pos += len(tag)
+
+	end := strings.Index(s.sql[s.pos:], tag)
+
+	if end < 0 {
+		s.pos = len(s.sql)
+	} else {
+		s.pos += end + len(tag)
+	}
+}
+
+func (s *splitter) skipLineComment() {
+	end := strings.IndexByte(s.sql[s.pos:], '\n')
+
+	if end < 0 {
+		s.pos = len(s.sql)
+	} else {
+		s.pos += end + 1
+	}
+}
+
+func (s *splitter) skipBlockComment() {
+	depth := 0
+
+	for s.pos < len(s.sql) {
+		switch {
+		case s.sql[s.pos] == '/' && s.peek(1) == '*':
+			depth++
+			s.pos += 2
+		case s.sql[s.pos] == '*' && s.peek(1) == '/':
+			depth--
+			s.pos += 2
+
+			if depth == 0 {
+				return
+			}
+		default:
+			s.pos++
+		}
+	}
+}
+
+func (s *splitter) markStart() {
+	if s.start < 0 {
+		s.start = s.pos
+	}
+}
+
+func (s *splitter) endStatement() {
+	if s.blockDepth > 0 && s.pos < len(s.sql) {
+		return
+	}
+
+	if s.start >= 0 {
+		s.statements = append(s.statements, Statement{
+			Text:  strings.TrimRightFunc(s.sql[s.start:s.pos], unicode.IsSpace),
+			Start: s.start,
+			Line:  strings.Count(s.sql[:s.start], "\n") + 1,
+			Words: s.words,
+		})
+	}
+
+	s.start = -1
+	s.words = nil
+	s.blockDepth = 0
+}
+
+func (s *splitter) peek(offset int) byte {
+	if s.pos+offset < len(s.sql) {
+		return s.sql[s.pos+offset]
+	}
+
+	return 0
+}
+
+func isSpace(ch byte) bool {
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' || ch == '\f' || ch == '\v'
+}
+
+func isIdentStart(sql string, pos int) bool {
+	r, _ := utf8.DecodeRuneInString(sql[pos:])
+
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentPart(sql string, pos int) bool {
+	r, _ := utf8.DecodeRuneInString(sql[pos:])
+
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
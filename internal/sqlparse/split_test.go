@@ -0,0 +1,99 @@
+package sqlparse_test
+
+import (
+	"testing"
+
+	"github.com/servletcloud/Andmerada/internal/sqlparse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplit(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	texts := func(statements []sqlparse.Statement) []string {
+		result := make([]string, 0, len(statements))
+
+		for _, statement := range statements {
+			result = append(result, statement.Text)
+		}
+
+		return result
+	}
+
+	t.Run("splits by semicolons", func(t *testing.T) {
+		t.Parallel()
+
+		actual := sqlparse.Split("SELECT 1; SELECT 2;\nSELECT 3")
+
+		assert.Equal(t, []string{"SELECT 1;", "SELECT 2;", "SELECT 3"}, texts(actual))
+	})
+
+	t.Run("skips empty statements and comments", func(t *testing.T) {
+		t.Parallel()
+
+		sql := "-- header; comment\n;;\n/* block; /* nested; */ comment */\nSELECT 1; -- trailing;\n"
+
+		assert.Equal(t, []string{"SELECT 1;"}, texts(sqlparse.Split(sql)))
+	})
+
+	t.Run("keeps semicolons in strings and identifiers", func(t *testing.T) {
+		t.Parallel()
+
+		sql := `SELECT 'a;b', 'it''s;', "we;ird""id"; SELECT E'\';', E'x\\'; SELECT 2`
+
+		assert.Equal(t, []string{
+			`SELECT 'a;b', 'it''s;', "we;ird""id";`,
+			`SELECT E'\';', E'x\\';`,
+			`SELECT 2`,
+		}, texts(sqlparse.Split(sql)))
+	})
+
+	t.Run("keeps semicolons in dollar quotes", func(t *testing.T) {
+		t.Parallel()
+
+		sql := "DO $$ BEGIN PERFORM 1; END $$; CREATE FUNCTION f() RETURNS int AS $fn$ SELECT 1; $fn$ LANGUAGE sql;" +
+			" SELECT $1;"
+
+		actual := sqlparse.Split(sql)
+
+		require.Len(t, actual, 3)
+		assert.Equal(t, "DO $$ BEGIN PERFORM 1; END $$;", actual[0].Text)
+		assert.Equal(t, "SELECT $1;", actual[2].Text)
+	})
+
+	t.Run("keeps semicolons in BEGIN ATOMIC bodies", func(t *testing.T) {
+		t.Parallel()
+
+		sql := "CREATE OR REPLACE FUNCTION f() RETURNS int LANGUAGE sql BEGIN ATOMIC " +
+			"SELECT CASE WHEN true THEN 1 END; SELECT 2; END; SELECT 3;"
+
+		actual := sqlparse.Split(sql)
+
+		require.Len(t, actual, 2)
+		assert.Equal(t, "SELECT 3;", actual[1].Text)
+	})
+
+	t.Run("reports start offset and line of each statement", func(t *testing.T) {
+		t.Parallel()
+
+		sql := "-- comment\nSELECT 1;\n\n  SELECT\n 2;"
+
+		actual := sqlparse.Split(sql)
+
+		require.Len(t, actual, 2)
+		assert.Equal(t, 2, actual[0].Line)
+		assert.Equal(t, 11, actual[0].Start)
+		assert.Equal(t, 4, actual[1].Line)
+		assert.Equal(t, "SELECT\n 2;", actual[1].Text)
+	})
+
+	t.Run("collects upper-cased words outside of literals", func(t *testing.T) {
+		t.Parallel()
+
+		actual := sqlparse.Split(`create index "CONCURRENTLY" on users (name) where name <> 'x'`)
+
+		require.Len(t, actual, 1)
+		assert.Equal(t, []string{"CREATE", "INDEX", "ON", "USERS", "NAME", "WHERE", "NAME"}, actual[0].Words)
+	})
+}
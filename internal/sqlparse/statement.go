@@ -0,0 +1,129 @@
+package sqlparse
+
+import (
+	"slices"
+)
+
+type Kind int
+
+const (
+	KindOther Kind = iota
+	KindBegin
+	KindCommit
+	KindRollback
+	KindSavepoint
+	KindReleaseSavepoint
+	KindRollbackToSavepoint
+	KindTwoPhaseCommit
+)
+
+func (s *Statement) Kind() Kind { //nolint:cyclop
+	switch s.word(0) {
+	case "BEGIN":
+		return KindBegin
+	case "START":
+		if s.word(1) == "TRANSACTION" {
+			return KindBegin
+		}
+	case "COMMIT", "END":
+		if s.word(1) == "PREPARED" {
+			return KindTwoPhaseCommit
+		}
+
+		return KindCommit
+	case "ROLLBACK", "ABORT":
+		return s.rollbackKind()
+	case "SAVEPOINT":
+		return KindSavepoint
+	case "RELEASE":
+		return KindReleaseSavepoint
+	case "PREPARE":
+		if s.word(1) == "TRANSACTION" {
+			return KindTwoPhaseCommit
+		}
+	}
+
+	return KindOther
+}
+
+func (s *Statement) IsTransactionControl() bool {
+	return s.Kind() != KindOther
+}
+
+// IsNonTransactional reports statements that PostgreSQL refuses to run inside
+// a transaction block.
+func (s *Statement) IsNonTransactional() bool {
+	first, second := s.word(0), s.word(1)
+
+	switch first {
+	case "VACUUM":
+		return true
+	case "CREATE", "DROP":
+		if second == "DATABASE" || second == "TABLESPACE" || second == "SUBSCRIPTION" {
+			return true
+		}
+
+		return s.isIndexConcurrently()
+	case "REINDEX":
+		return second == "DATABASE" || second == "SYSTEM" || s.hasWord("CONCURRENTLY")
+	case "ALTER":
+		if second == "SYSTEM" {
+			return true
+		}
+
+		return second == "TABLE" && s.hasWord("DETACH") && s.hasWord("CONCURRENTLY")
+	}
+
+	return false
+}
+
+// IsSessionSet reports SET statements whose effect outlives the transaction, e.g. SET search_path
+// or SET ROLE, as opposed to SET LOCAL, SET TRANSACTION and SET CONSTRAINTS.
+func (s *Statement) IsSessionSet() bool {
+	if s.word(0) != "SET" {
+		return false
+	}
+
+	switch s.word(1) {
+	case "LOCAL", "TRANSACTION", "CONSTRAINTS":
+		return false
+	}
+
+	return true
+}
+
+func (s *Statement) isIndexConcurrently() bool {
+	index := slices.Index(s.Words, "INDEX")
+
+	return index > 0 && index <= 2 && s.word(index+1) == "CONCURRENTLY"
+}
+
+func (s *Statement) rollbackKind() Kind {
+	next := s.word(1)
+
+	if next == "PREPARED" {
+		return KindTwoPhaseCommit
+	}
+
+	if next == "WORK" || next == "TRANSACTION" {
+		next = s.word(2)
+	}
+
+	if next == "TO" {
+		return KindRollbackToSavepoint
+	}
+
+	return KindRollback
+}
+
+func (s *Statement) hasWord(word string) bool {
+	return slices.Contains(s.Words, word)
+}
+
+func (s *Statement) word(index int) string {
+	if index < len(s.Words) {
+		return s.Words[index]
+	}
+
+	return ""
+}
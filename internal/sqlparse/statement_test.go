@@ -0,0 +1,87 @@
+package sqlparse_test
+
+import (
+	"testing"
+
+	"github.com/servletcloud/Andmerada/internal/sqlparse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatement_Kind(t *testing.T) {
+	t.Parallel()
+
+	expected := map[string]sqlparse.Kind{
+		"BEGIN":                              sqlparse.KindBegin,
+		"begin isolation level serializable": sqlparse.KindBegin,
+		"START TRANSACTION":                  sqlparse.KindBegin,
+		"COMMIT":                             sqlparse.KindCommit,
+		"END":                                sqlparse.KindCommit,
+		"ROLLBACK":                           sqlparse.KindRollback,
+		"ABORT":                              sqlparse.KindRollback,
+		"ROLLBACK TO SAVEPOINT a":            sqlparse.KindRollbackToSavepoint,
+		"ROLLBACK WORK TO a":                 sqlparse.KindRollbackToSavepoint,
+		"SAVEPOINT a":                        sqlparse.KindSavepoint,
+		"RELEASE SAVEPOINT a":                sqlparse.KindReleaseSavepoint,
+		"PREPARE TRANSACTION 'x'":            sqlparse.KindTwoPhaseCommit,
+		"COMMIT PREPARED 'x'":                sqlparse.KindTwoPhaseCommit,
+		"PREPARE stmt AS SELECT 1":           sqlparse.KindOther,
+		"SELECT 1":                           sqlparse.KindOther,
+	}
+
+	for sql, kind := range expected {
+		statements := sqlparse.Split(sql)
+		require.Len(t, statements, 1)
+
+		assert.Equal(t, kind, statements[0].Kind(), sql)
+	}
+}
+
+func TestStatement_IsNonTransactional(t *testing.T) {
+	t.Parallel()
+
+	expected := map[string]bool{
+		"CREATE INDEX CONCURRENTLY idx ON users (name)":             true,
+		"create unique index concurrently idx on users (name)":      true,
+		"DROP INDEX CONCURRENTLY idx":                               true,
+		"REINDEX TABLE CONCURRENTLY users":                          true,
+		"VACUUM ANALYZE users":                                      true,
+		"CREATE DATABASE test":                                      true,
+		"ALTER SYSTEM SET work_mem = '64MB'":                        true,
+		"ALTER TABLE events DETACH PARTITION events_1 CONCURRENTLY": true,
+		"CREATE INDEX idx ON users (name)":                          false,
+		`CREATE INDEX "concurrently" ON users (name)`:               false,
+		"ALTER TABLE users ADD COLUMN age INTEGER":                  false,
+	}
+
+	for sql, nonTransactional := range expected {
+		statements := sqlparse.Split(sql)
+		require.Len(t, statements, 1)
+
+		assert.Equal(t, nonTransactional, statements[0].IsNonTransactional(), sql)
+	}
+}
+
+func TestStatement_IsSessionSet(t *testing.T) {
+	t.Parallel()
+
+	expected := map[string]bool{
+		"SET search_path TO app, public":                   true,
+		"set work_mem = '64MB'":                            true,
+		"SET SESSION statement_timeout = 0":                true,
+		"SET ROLE app_owner":                               true,
+		"SET SESSION AUTHORIZATION app_owner":              true,
+		"SET LOCAL lock_timeout = '5s'":                    false,
+		"SET TRANSACTION ISOLATION LEVEL SERIALIZABLE":     false,
+		"SET CONSTRAINTS ALL DEFERRED":                     false,
+		"ALTER TABLE users ALTER COLUMN name SET NOT NULL": false,
+		"RESET ALL": false,
+	}
+
+	for sql, sessionSet := range expected {
+		statements := sqlparse.Split(sql)
+		require.Len(t, statements, 1)
+
+		assert.Equal(t, sessionSet, statements[0].IsSessionSet(), sql)
+	}
+}
@@ -3,6 +3,7 @@ package ymlutil
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"strings"
 
@@ -38,18 +39,90 @@ func LoadFromFile(path string, schema string, out any) error {
 		return fmt.Errorf("cannot read file %q:\n%w", path, err)
 	}
 
-	if err = yaml.Unmarshal(content, out); err != nil {
-		return fmt.Errorf("cannot parse YML file %q:\n%w", path, err)
+	return load(path, content, schema, out)
+}
+
+// LoadWithOverlay loads the file at path with the optional file at overlayPath merged on top of it.
+// Mappings are merged key by key, any other value of the overlay replaces the one of the file.
+// The merged document is validated against the schema, so the overlay may be partial.
+func LoadWithOverlay(path string, overlayPath string, schema string, out any) error {
+	overlay, err := os.ReadFile(overlayPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return LoadFromFile(path, schema, out)
+		}
+
+		return fmt.Errorf("cannot read file %q:\n%w", overlayPath, err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return err //nolint:wrapcheck
+		}
+
+		return fmt.Errorf("cannot read file %q:\n%w", path, err)
+	}
+
+	merged, err := mergeYML(content, overlay)
+	if err != nil {
+		return fmt.Errorf("cannot merge %q into %q:\n%w", overlayPath, path, err)
+	}
+
+	return load(path+" merged with "+overlayPath, merged, schema, out)
+}
+
+func load(name string, content []byte, schema string, out any) error {
+	if err := yaml.Unmarshal(content, out); err != nil {
+		return fmt.Errorf("cannot parse YML file %q:\n%w", name, err)
 	}
 
 	result, err := validate(content, schema)
 	if err != nil {
-		return fmt.Errorf("cannot validate JSON schema in %q:\n%w", path, err)
+		return fmt.Errorf("cannot validate JSON schema in %q:\n%w", name, err)
 	}
 
 	if !result.Valid() {
-		return fmt.Errorf("cannot validate schema in %q:\n%w", path, NewValidationError(result))
+		return fmt.Errorf("cannot validate schema in %q:\n%w", name, NewValidationError(result))
 	}
 
 	return nil
 }
+
+func mergeYML(base []byte, overlay []byte) ([]byte, error) {
+	var baseData, overlayData map[string]any
+
+	if err := yaml.Unmarshal(base, &baseData); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal YML to map[string]any:\n%w", err)
+	}
+
+	if err := yaml.Unmarshal(overlay, &overlayData); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal YML to map[string]any:\n%w", err)
+	}
+
+	result, err := yaml.Marshal(mergeMaps(baseData, overlayData))
+	if err != nil {
+		return nil, fmt.Errorf("cannot marshal map[string]any to YML:\n%w", err)
+	}
+
+	return result, nil
+}
+
+func mergeMaps(base map[string]any, overlay map[string]any) map[string]any {
+	result := make(map[string]any, len(base)+len(overlay))
+
+	maps.Copy(result, base)
+
+	for key, value := range overlay {
+		baseMap, baseIsMap := result[key].(map[string]any)
+		overlayMap, overlayIsMap := value.(map[string]any)
+
+		if baseIsMap && overlayIsMap {
+			result[key] = mergeMaps(baseMap, overlayMap)
+		} else {
+			result[key] = value
+		}
+	}
+
+	return result
+}